@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BatchHandler struct {
+	batchService service.BatchServiceInterface
+}
+
+func NewBatchHandler(batchService service.BatchServiceInterface) *BatchHandler {
+	return &BatchHandler{batchService: batchService}
+}
+
+func (h *BatchHandler) ReceiveGoods(c *fiber.Ctx) error {
+	var receipt model.GoodsReceipt
+	err := c.BodyParser(&receipt)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	err = h.batchService.ReceiveGoods(&receipt)
+	if errors.Is(err, repository.ErrLocationNotFound) || errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(receipt)
+}
+
+func (h *BatchHandler) GetReceipts(c *fiber.Ctx) error {
+	receipts, err := h.batchService.GetReceipts(c.QueryInt("location_id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get goods receipts",
+		})
+	}
+	return c.JSON(receipts)
+}
+
+func (h *BatchHandler) GetReceiptByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid goods receipt ID",
+		})
+	}
+	receipt, err := h.batchService.GetReceiptByID(id)
+	if errors.Is(err, repository.ErrGoodsReceiptNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Goods receipt not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get goods receipt",
+		})
+	}
+	return c.JSON(receipt)
+}
+
+func (h *BatchHandler) GetByProduct(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid product ID",
+		})
+	}
+	batches, err := h.batchService.GetBatches(id, c.QueryInt("location_id"), c.QueryBool("include_empty"))
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Product not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get product batches",
+		})
+	}
+	return c.JSON(batches)
+}
+
+func (h *BatchHandler) Expiring(c *fiber.Ctx) error {
+	batches, err := h.batchService.GetExpiring(c.QueryInt("days"), c.QueryInt("location_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(batches)
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CartHandler struct {
+	cartService service.CartServiceInterface
+}
+
+func NewCartHandler(cartService service.CartServiceInterface) *CartHandler {
+	return &CartHandler{cartService: cartService}
+}
+
+func (h *CartHandler) GetAll(c *fiber.Ctx) error {
+	carts, err := h.cartService.GetAll(c.Query("cashier"), c.Query("status"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(carts)
+}
+
+func (h *CartHandler) Create(c *fiber.Ctx) error {
+	var cart model.Cart
+	err := c.BodyParser(&cart)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	created, err := h.cartService.Create(&cart)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+func (h *CartHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid cart ID",
+		})
+	}
+	cart, err := h.cartService.GetByID(id)
+	if errors.Is(err, repository.ErrCartNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Cart not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get cart",
+		})
+	}
+	return c.JSON(cart)
+}
+
+func (h *CartHandler) AddItem(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid cart ID",
+		})
+	}
+	var item model.CheckoutItem
+	err = c.BodyParser(&item)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	cart, err := h.cartService.AddItem(id, item)
+	if err != nil {
+		return cartError(c, err)
+	}
+	return c.JSON(cart)
+}
+
+func (h *CartHandler) UpdateItem(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid cart ID",
+		})
+	}
+	productID, err := strconv.Atoi(c.Params("product_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid product ID",
+		})
+	}
+	var item model.CheckoutItem
+	err = c.BodyParser(&item)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	item.ProductID = productID
+
+	cart, err := h.cartService.UpdateItem(id, item)
+	if err != nil {
+		return cartError(c, err)
+	}
+	return c.JSON(cart)
+}
+
+func (h *CartHandler) RemoveItem(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid cart ID",
+		})
+	}
+	productID, err := strconv.Atoi(c.Params("product_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid product ID",
+		})
+	}
+
+	cart, err := h.cartService.RemoveItem(id, productID)
+	if err != nil {
+		return cartError(c, err)
+	}
+	return c.JSON(cart)
+}
+
+func (h *CartHandler) Park(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid cart ID",
+		})
+	}
+	cart, err := h.cartService.Park(id)
+	if err != nil {
+		return cartError(c, err)
+	}
+	return c.JSON(cart)
+}
+
+func (h *CartHandler) Resume(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid cart ID",
+		})
+	}
+	var request model.ResumeCartRequest
+	if len(c.Body()) > 0 {
+		err = c.BodyParser(&request)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid request body",
+			})
+		}
+	}
+
+	cart, err := h.cartService.Resume(id, &request)
+	if err != nil {
+		return cartError(c, err)
+	}
+	return c.JSON(cart)
+}
+
+func (h *CartHandler) Discard(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid cart ID",
+		})
+	}
+	err = h.cartService.Discard(id)
+	if err != nil {
+		return cartError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"message": "Cart discarded successfully",
+	})
+}
+
+func (h *CartHandler) Checkout(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid cart ID",
+		})
+	}
+	var request model.CartCheckoutRequest
+	if len(c.Body()) > 0 {
+		err = c.BodyParser(&request)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid request body",
+			})
+		}
+	}
+
+	transaction, err := h.cartService.Checkout(id, &request)
+	if err != nil {
+		return cartError(c, err)
+	}
+	return c.JSON(transaction)
+}
+
+// cartError maps cart service errors to responses: unknown carts and lines
+// are 404, carts in the wrong state are 409, anything else is a bad request.
+func cartError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	switch {
+	case errors.Is(err, repository.ErrCartNotFound), errors.Is(err, repository.ErrCartItemNotFound), errors.Is(err, repository.ErrProductNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, service.ErrCartNotOpen), errors.Is(err, service.ErrCartNotParked), errors.Is(err, service.ErrCartClosed), errors.Is(err, repository.ErrDayClosed):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"message": err.Error(),
+	})
+}
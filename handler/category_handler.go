@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"product-api/model"
+	"product-api/repository"
 	"product-api/service"
 	"strconv"
 
@@ -18,7 +20,8 @@ func NewCategoryHandler(categoryService service.CategoryServiceInterface) *Categ
 }
 
 func (h *CategoryHandler) GetAll(c *fiber.Ctx) error {
-	categories, err := h.categoryService.GetAll()
+	includeDeleted := c.QueryBool("include_deleted")
+	categories, err := h.categoryService.GetAll(includeDeleted)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to get categories",
@@ -97,12 +100,44 @@ func (h *CategoryHandler) Delete(c *fiber.Ctx) error {
 		})
 	}
 	err = h.categoryService.Delete(id)
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Category not found",
+		})
+	}
+	if errors.Is(err, service.ErrCategoryInUse) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": err.Error(),
+			"message": "Failed to delete category",
 		})
 	}
 	return c.JSON(fiber.Map{
 		"message": "Category deleted successfully",
 	})
 }
+
+func (h *CategoryHandler) Restore(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid category ID",
+		})
+	}
+	category, err := h.categoryService.Restore(id)
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Deleted category not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to restore category",
+		})
+	}
+	return c.JSON(category)
+}
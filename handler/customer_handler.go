@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CustomerHandler struct {
+	customerService service.CustomerServiceInterface
+}
+
+func NewCustomerHandler(customerService service.CustomerServiceInterface) *CustomerHandler {
+	return &CustomerHandler{customerService: customerService}
+}
+
+func (h *CustomerHandler) GetAll(c *fiber.Ctx) error {
+	search := c.Query("search")
+	customers, err := h.customerService.GetAll(search)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get customers",
+		})
+	}
+	return c.JSON(customers)
+}
+
+func (h *CustomerHandler) Create(c *fiber.Ctx) error {
+	var customer model.Customer
+	err := c.BodyParser(&customer)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	err = h.customerService.Create(&customer)
+	if errors.Is(err, repository.ErrCustomerPhoneExists) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(customer)
+}
+
+func (h *CustomerHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid customer ID",
+		})
+	}
+	customer, err := h.customerService.GetByID(id)
+	if errors.Is(err, repository.ErrCustomerNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Customer not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get customer",
+		})
+	}
+	return c.JSON(customer)
+}
+
+func (h *CustomerHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid customer ID",
+		})
+	}
+	var customer model.Customer
+	err = c.BodyParser(&customer)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	customer.ID = id
+	err = h.customerService.Update(&customer)
+	if errors.Is(err, repository.ErrCustomerNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Customer not found",
+		})
+	}
+	if errors.Is(err, repository.ErrCustomerPhoneExists) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(customer)
+}
+
+func (h *CustomerHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid customer ID",
+		})
+	}
+	err = h.customerService.Delete(id)
+	if errors.Is(err, repository.ErrCustomerNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Customer not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to delete customer",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": "Customer deleted successfully",
+	})
+}
+
+func (h *CustomerHandler) GetTransactions(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid customer ID",
+		})
+	}
+	response, err := h.customerService.GetTransactions(id)
+	if errors.Is(err, repository.ErrCustomerNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Customer not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get customer transactions",
+		})
+	}
+	return c.JSON(response)
+}
+
+func (h *CustomerHandler) GetPoints(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid customer ID",
+		})
+	}
+	points, err := h.customerService.GetPoints(id)
+	if errors.Is(err, repository.ErrCustomerNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Customer not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get customer points",
+		})
+	}
+	return c.JSON(points)
+}
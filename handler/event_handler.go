@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"product-api/model"
+	"product-api/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// keepAliveInterval keeps idle streams from being cut by proxies.
+const keepAliveInterval = 15 * time.Second
+
+// writeTimeout drops WebSocket clients that stop reading, and
+// maxClientMessage bounds what a client may send on the push-only socket.
+const (
+	writeTimeout     = 10 * time.Second
+	maxClientMessage = 4096
+)
+
+type EventHandler struct {
+	salesFeed      service.SalesFeedInterface
+	allowedOrigins []string
+}
+
+// NewEventHandler creates the event handler. Browser pages from
+// allowedOrigins, besides the server's own origin, may open the WebSocket
+// feed.
+func NewEventHandler(salesFeed service.SalesFeedInterface, allowedOrigins []string) *EventHandler {
+	return &EventHandler{salesFeed: salesFeed, allowedOrigins: allowedOrigins}
+}
+
+// Sales streams the live sales feed as Server-Sent Events, starting with a
+// snapshot of today's totals. The feed is subscribed to before the snapshot
+// is taken so no sale falls between the two; a sale counted in both only
+// repeats totals that every event carries anyway.
+func (h *EventHandler) Sales(c *fiber.Ctx) error {
+	locationID := c.QueryInt("location_id")
+	events, unsubscribe := h.salesFeed.Subscribe(locationID)
+	snapshot, err := h.salesFeed.Snapshot(locationID)
+	if err != nil {
+		unsubscribe()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get sales totals",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		keepAlive := time.NewTicker(keepAliveInterval)
+		defer keepAlive.Stop()
+
+		if writeSSE(w, snapshot) != nil {
+			return
+		}
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok || writeSSE(w, event) != nil {
+					return
+				}
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+				if w.Flush() != nil {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// SalesWebSocketUpgrade lets only WebSocket upgrades from an allowed origin
+// through to SalesWebSocket. Browsers send the Origin header on every
+// WebSocket handshake and do not apply CORS to it, so without this check any
+// web page could read the feed.
+func (h *EventHandler) SalesWebSocketUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{
+			"message": "WebSocket upgrade required",
+		})
+	}
+	if !h.allowedOrigin(c) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Origin not allowed",
+		})
+	}
+	return c.Next()
+}
+
+// SalesWebSocket pushes the same events as Sales over a WebSocket, one JSON
+// message per event. Like Sales, it subscribes before taking the snapshot.
+func (h *EventHandler) SalesWebSocket(conn *websocket.Conn) {
+	locationID, _ := strconv.Atoi(conn.Query("location_id"))
+	events, unsubscribe := h.salesFeed.Subscribe(locationID)
+	defer unsubscribe()
+	snapshot, err := h.salesFeed.Snapshot(locationID)
+	if err != nil {
+		message := websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "Failed to get sales totals")
+		conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(writeTimeout))
+		return
+	}
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	// Reading answers pings and notices when the client goes away; the
+	// client has nothing to say on this push-only connection.
+	conn.SetReadLimit(maxClientMessage)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			_, _, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	if writeWebSocket(conn, snapshot) != nil {
+		return
+	}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok || writeWebSocket(conn, event) != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)) != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// allowedOrigin accepts requests without an Origin header, which do not come
+// from a browser, requests from the server's own origin and requests from
+// one of the configured origins. "*" allows every origin.
+func (h *EventHandler) allowedOrigin(c *fiber.Ctx) bool {
+	origin := c.Get(fiber.HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err == nil && strings.EqualFold(parsed.Host, c.Hostname()) {
+		return true
+	}
+	for _, allowed := range h.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeSSE(w *bufio.Writer, event model.SalesEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if event.ID != 0 {
+		fmt.Fprintf(w, "id: %d\n", event.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return w.Flush()
+}
+
+func writeWebSocket(conn *websocket.Conn, event model.SalesEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
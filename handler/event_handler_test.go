@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"io"
+	"net/http/httptest"
+	"product-api/model"
+	"product-api/service"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func upgradeStatus(t *testing.T, allowedOrigins []string, origin string, upgrade bool) int {
+	t.Helper()
+	h := NewEventHandler(nil, allowedOrigins)
+	app := fiber.New()
+	app.Get("/api/events/sales/ws", h.SalesWebSocketUpgrade, func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("GET", "http://pos.example.com/api/events/sales/ws", nil)
+	if upgrade {
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+	}
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestSalesWebSocketUpgradeOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		upgrade bool
+		want    int
+	}{
+		{"not an upgrade", nil, "", false, fiber.StatusUpgradeRequired},
+		{"no origin", nil, "", true, fiber.StatusNoContent},
+		{"same origin", nil, "https://pos.example.com", true, fiber.StatusNoContent},
+		{"foreign origin", nil, "https://evil.example.net", true, fiber.StatusForbidden},
+		{"listed origin", []string{"https://kasir.example.com"}, "https://kasir.example.com", true, fiber.StatusNoContent},
+		{"unlisted origin", []string{"https://kasir.example.com"}, "http://kasir.example.com", true, fiber.StatusForbidden},
+		{"any origin", []string{"*"}, "https://evil.example.net", true, fiber.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := upgradeStatus(t, tt.allowed, tt.origin, tt.upgrade)
+			if got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// racingSalesFeed publishes a checkout while the snapshot is being taken, as
+// a sale made between subscribing and reading the totals would.
+type racingSalesFeed struct {
+	service.SalesFeedInterface
+	events chan model.SalesEvent
+}
+
+func (f *racingSalesFeed) Subscribe(locationID int) (<-chan model.SalesEvent, func()) {
+	f.events = make(chan model.SalesEvent, 1)
+	return f.events, func() {}
+}
+
+func (f *racingSalesFeed) Snapshot(locationID int) (model.SalesEvent, error) {
+	if f.events != nil {
+		f.events <- model.SalesEvent{ID: 1, Type: model.SalesEventCheckout}
+		close(f.events)
+	}
+	return model.SalesEvent{Type: model.SalesEventTotals}, nil
+}
+
+func TestSalesStreamsSaleMadeDuringSnapshot(t *testing.T) {
+	h := NewEventHandler(&racingSalesFeed{}, nil)
+	app := fiber.New()
+	app.Get("/api/events/sales", h.Sales)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/events/sales", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totals := strings.Index(string(body), "event: "+model.SalesEventTotals+"\n")
+	checkout := strings.Index(string(body), "event: "+model.SalesEventCheckout+"\n")
+	if totals < 0 || checkout < totals {
+		t.Errorf("stream = %q, want the totals followed by the checkout", body)
+	}
+}
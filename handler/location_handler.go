@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LocationHandler struct {
+	locationService service.LocationServiceInterface
+}
+
+func NewLocationHandler(locationService service.LocationServiceInterface) *LocationHandler {
+	return &LocationHandler{locationService: locationService}
+}
+
+func (h *LocationHandler) GetAll(c *fiber.Ctx) error {
+	locations, err := h.locationService.GetAll()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get locations",
+		})
+	}
+	return c.JSON(locations)
+}
+
+func (h *LocationHandler) Create(c *fiber.Ctx) error {
+	var location model.Location
+	err := c.BodyParser(&location)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	err = h.locationService.Create(&location)
+	if errors.Is(err, repository.ErrLocationCodeExists) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(location)
+}
+
+func (h *LocationHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid location ID",
+		})
+	}
+	location, err := h.locationService.GetByID(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Location not found",
+		})
+	}
+	return c.JSON(location)
+}
+
+func (h *LocationHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid location ID",
+		})
+	}
+	var location model.Location
+	err = c.BodyParser(&location)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	location.ID = id
+
+	err = h.locationService.Update(&location)
+	if errors.Is(err, repository.ErrLocationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Location not found",
+		})
+	}
+	if errors.Is(err, repository.ErrLocationCodeExists) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(location)
+}
+
+func (h *LocationHandler) GetStock(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid location ID",
+		})
+	}
+	levels, err := h.locationService.GetStock(id)
+	if errors.Is(err, repository.ErrLocationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Location not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get location stock",
+		})
+	}
+	return c.JSON(levels)
+}
+
+func (h *LocationHandler) SetStock(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid location ID",
+		})
+	}
+	productID, err := strconv.Atoi(c.Params("product_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid product ID",
+		})
+	}
+	var request model.SetStockRequest
+	err = c.BodyParser(&request)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	level, err := h.locationService.SetStock(id, productID, request.Quantity)
+	if errors.Is(err, repository.ErrLocationNotFound) || errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(level)
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"product-api/model"
+	"product-api/repository"
 	"product-api/service"
 	"strconv"
 
@@ -16,9 +18,10 @@ func NewProductHandler(productService service.ProductServiceInterface) *ProductH
 	return &ProductHandler{productService: productService}
 }
 
-func (h *ProductHandler) HandleProducts(c *fiber.Ctx) error {	
+func (h *ProductHandler) HandleProducts(c *fiber.Ctx) error {
 	name := c.Query("name")
-	products, err := h.productService.GetAll(name)
+	includeDeleted := c.QueryBool("include_deleted")
+	products, err := h.productService.GetAll(name, includeDeleted)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to get products",
@@ -102,9 +105,14 @@ func (h *ProductHandler) Delete(c *fiber.Ctx) error {
 	}
 
 	err = h.productService.Delete(id)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Product not found",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": err.Error(),
+			"message": "Failed to delete product",
 		})
 	}
 
@@ -112,3 +120,143 @@ func (h *ProductHandler) Delete(c *fiber.Ctx) error {
 		"message": "Product deleted successfully",
 	})
 }
+
+func (h *ProductHandler) Restore(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid product ID",
+		})
+	}
+
+	product, err := h.productService.Restore(id)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Deleted product not found",
+		})
+	}
+	if errors.Is(err, service.ErrCategoryDeleted) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to restore product",
+		})
+	}
+
+	return c.JSON(product)
+}
+
+func (h *ProductHandler) GetPrices(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid product ID",
+		})
+	}
+
+	prices, err := h.productService.GetPrices(id)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Product not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get product prices",
+		})
+	}
+
+	return c.JSON(prices)
+}
+
+func (h *ProductHandler) SchedulePrice(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid product ID",
+		})
+	}
+
+	var request model.SchedulePriceRequest
+	err = c.BodyParser(&request)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	price, err := h.productService.SchedulePrice(id, &request)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Product not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(price)
+}
+
+func (h *ProductHandler) CancelScheduledPrice(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid product ID",
+		})
+	}
+	priceID, err := strconv.Atoi(c.Params("price_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid price ID",
+		})
+	}
+
+	err = h.productService.CancelScheduledPrice(id, priceID)
+	if errors.Is(err, repository.ErrProductPriceNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Scheduled price not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to cancel scheduled price",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Scheduled price cancelled successfully",
+	})
+}
+
+func (h *ProductHandler) GetStock(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid product ID",
+		})
+	}
+
+	stock, err := h.productService.GetStock(id)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Product not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get product stock",
+		})
+	}
+
+	return c.JSON(stock)
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"product-api/utils/receipt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReceiptHandler struct {
+	receiptService service.ReceiptServiceInterface
+}
+
+func NewReceiptHandler(receiptService service.ReceiptServiceInterface) *ReceiptHandler {
+	return &ReceiptHandler{receiptService: receiptService}
+}
+
+func (h *ReceiptHandler) Render(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid transaction ID",
+		})
+	}
+	format := c.Query("format", model.ReceiptFormatText)
+	paper := c.QueryInt("paper", 80)
+	// Only the print right after checkout opens the cash drawer
+	openDrawer := c.QueryBool("drawer")
+
+	body, contentType, err := h.receiptService.Render(id, format, paper, openDrawer)
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Transaction not found",
+		})
+	}
+	if errors.Is(err, receipt.ErrUnsupportedFormat) || errors.Is(err, receipt.ErrUnsupportedPaper) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to render receipt",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(body)
+}
+
+func (h *ReceiptHandler) GetSettings(c *fiber.Ctx) error {
+	settings, err := h.receiptService.GetSettings()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get receipt settings",
+		})
+	}
+	return c.JSON(settings)
+}
+
+func (h *ReceiptHandler) UpdateSettings(c *fiber.Ctx) error {
+	var settings model.ReceiptSettings
+	err := c.BodyParser(&settings)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	err = h.receiptService.UpdateSettings(&settings)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to update receipt settings",
+		})
+	}
+	return c.JSON(settings)
+}
+
+func (h *ReceiptHandler) GetTemplate(c *fiber.Ctx) error {
+	template, err := h.receiptService.GetTemplate(c.Params("format"))
+	if errors.Is(err, receipt.ErrUnsupportedFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get receipt template",
+		})
+	}
+	return c.JSON(template)
+}
+
+func (h *ReceiptHandler) UpdateTemplate(c *fiber.Ctx) error {
+	var template model.ReceiptTemplate
+	err := c.BodyParser(&template)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	template.Format = c.Params("format")
+	err = h.receiptService.UpdateTemplate(&template)
+	if errors.Is(err, receipt.ErrUnsupportedFormat) || errors.Is(err, receipt.ErrInvalidTemplate) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to update receipt template",
+		})
+	}
+	return c.JSON(template)
+}
+
+func (h *ReceiptHandler) ResetTemplate(c *fiber.Ctx) error {
+	err := h.receiptService.ResetTemplate(c.Params("format"))
+	if errors.Is(err, receipt.ErrUnsupportedFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to reset receipt template",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": "Receipt template reset to default",
+	})
+}
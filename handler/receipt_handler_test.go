@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"product-api/utils/receipt"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// stubReceiptService fails every call the handlers make with err.
+type stubReceiptService struct {
+	service.ReceiptServiceInterface
+	err error
+}
+
+func (s *stubReceiptService) Render(transactionID int, format string, paper int, openDrawer bool) ([]byte, string, error) {
+	return nil, "", s.err
+}
+
+func (s *stubReceiptService) GetTemplate(format string) (*model.ReceiptTemplate, error) {
+	return nil, s.err
+}
+
+func (s *stubReceiptService) UpdateTemplate(template *model.ReceiptTemplate) error {
+	return s.err
+}
+
+func (s *stubReceiptService) ResetTemplate(format string) error {
+	return s.err
+}
+
+func TestReceiptHandlerErrorStatus(t *testing.T) {
+	errDB := errors.New("pq: connection refused")
+	errTemplate := fmt.Errorf("%w: template: receipt:1: unexpected EOF", receipt.ErrInvalidTemplate)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		err    error
+		want   int
+	}{
+		{"render not found", "GET", "/api/transactions/1/receipt", repository.ErrTransactionNotFound, fiber.StatusNotFound},
+		{"render unsupported format", "GET", "/api/transactions/1/receipt?format=docx", receipt.ErrUnsupportedFormat, fiber.StatusBadRequest},
+		{"render unsupported paper", "GET", "/api/transactions/1/receipt?paper=76", receipt.ErrUnsupportedPaper, fiber.StatusBadRequest},
+		{"render database failure", "GET", "/api/transactions/1/receipt", errDB, fiber.StatusInternalServerError},
+		{"get template unsupported format", "GET", "/api/receipt/templates/docx", receipt.ErrUnsupportedFormat, fiber.StatusBadRequest},
+		{"get template database failure", "GET", "/api/receipt/templates/text", errDB, fiber.StatusInternalServerError},
+		{"update template invalid", "PUT", "/api/receipt/templates/text", errTemplate, fiber.StatusBadRequest},
+		{"update template database failure", "PUT", "/api/receipt/templates/text", errDB, fiber.StatusInternalServerError},
+		{"reset template unsupported format", "DELETE", "/api/receipt/templates/docx", receipt.ErrUnsupportedFormat, fiber.StatusBadRequest},
+		{"reset template database failure", "DELETE", "/api/receipt/templates/text", errDB, fiber.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewReceiptHandler(&stubReceiptService{err: tt.err})
+			app := fiber.New()
+			app.Get("/api/transactions/:id/receipt", h.Render)
+			app.Get("/api/receipt/templates/:format", h.GetTemplate)
+			app.Put("/api/receipt/templates/:format", h.UpdateTemplate)
+			app.Delete("/api/receipt/templates/:format", h.ResetTemplate)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"content": "{{"}`))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
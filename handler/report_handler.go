@@ -0,0 +1,359 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"product-api/utils/export"
+	"product-api/utils/period"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var errInvalidTimezone = errors.New("tz must be a valid timezone name")
+
+type ReportHandler struct {
+	reportService service.ReportServiceInterface
+	reportConfig  model.ReportConfig
+}
+
+func NewReportHandler(reportService service.ReportServiceInterface, reportConfig model.ReportConfig) *ReportHandler {
+	return &ReportHandler{reportService: reportService, reportConfig: reportConfig}
+}
+
+// Today summarizes the current day in the report timezone.
+func (h *ReportHandler) Today(c *fiber.Ctx) error {
+	loc, err := reportTimezone(c, h.reportConfig)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	from, to := period.Day(time.Now(), loc)
+	filter := model.ReportFilter{From: from, To: to, Timezone: loc, LocationID: c.QueryInt("location_id")}
+	return h.summary(c, filter)
+}
+
+func (h *ReportHandler) Summary(c *fiber.Ctx) error {
+	filter, err := reportFilter(c, h.reportConfig)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return h.summary(c, filter)
+}
+
+func (h *ReportHandler) summary(c *fiber.Ctx, filter model.ReportFilter) error {
+	format := c.Query("format", export.FormatJSON)
+	if format == export.FormatJSON {
+		summary, err := h.reportService.Summary(filter, c.Query("compare"))
+		if err != nil {
+			return summaryError(c, err)
+		}
+		return c.JSON(summary)
+	}
+
+	body, contentType, err := h.reportService.ExportSummary(filter, c.Query("compare"), format)
+	if err != nil {
+		return summaryError(c, err)
+	}
+	return sendExport(c, "sales-summary", filter, format, contentType, body)
+}
+
+func summaryError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrInvalidCompare) || errors.Is(err, export.ErrUnsupportedFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Failed to get summary",
+	})
+}
+
+func (h *ReportHandler) Transactions(c *fiber.Ctx) error {
+	filter, err := reportFilter(c, h.reportConfig)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	format := c.Query("format", export.FormatJSON)
+	if format == export.FormatJSON {
+		transactions, err := h.reportService.Transactions(filter)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "Failed to get transactions",
+			})
+		}
+		return c.JSON(transactions)
+	}
+
+	body, contentType, err := h.reportService.ExportTransactions(filter, format)
+	if errors.Is(err, export.ErrUnsupportedFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to export transactions",
+		})
+	}
+	return sendExport(c, "transactions", filter, format, contentType, body)
+}
+
+func (h *ReportHandler) Timeseries(c *fiber.Ctx) error {
+	filter, err := reportFilter(c, h.reportConfig)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	timeseries, err := h.reportService.Timeseries(filter, c.Query("interval"))
+	if errors.Is(err, service.ErrInvalidInterval) || errors.Is(err, service.ErrHourlyRangeTooLong) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get timeseries report",
+		})
+	}
+	return c.JSON(timeseries)
+}
+
+func (h *ReportHandler) TopProducts(c *fiber.Ctx) error {
+	filter, err := reportFilter(c, h.reportConfig)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	top, err := h.reportService.TopProducts(filter, c.Query("by"), c.QueryInt("limit"))
+	if err != nil {
+		return topError(c, err)
+	}
+	return c.JSON(top)
+}
+
+func (h *ReportHandler) TopCategories(c *fiber.Ctx) error {
+	filter, err := reportFilter(c, h.reportConfig)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	top, err := h.reportService.TopCategories(filter, c.Query("by"), c.QueryInt("limit"))
+	if err != nil {
+		return topError(c, err)
+	}
+	return c.JSON(top)
+}
+
+func (h *ReportHandler) Breakdown(c *fiber.Ctx) error {
+	filter, err := reportFilter(c, h.reportConfig)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	group := c.Query("group")
+	format := c.Query("format", export.FormatJSON)
+	if format == export.FormatJSON {
+		breakdown, err := h.reportService.Breakdown(filter, group, c.Query("sort"), c.Query("order"))
+		if err != nil {
+			return breakdownError(c, err)
+		}
+		return c.JSON(breakdown)
+	}
+
+	body, contentType, err := h.reportService.ExportBreakdown(filter, group, c.Query("sort"), c.Query("order"), format)
+	if err != nil {
+		return breakdownError(c, err)
+	}
+	if group == "" {
+		group = model.BreakdownGroupProduct
+	}
+	return sendExport(c, "sales-by-"+group, filter, format, contentType, body)
+}
+
+func breakdownError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrInvalidGroup) || errors.Is(err, service.ErrInvalidSortKey) ||
+		errors.Is(err, service.ErrInvalidSortOrder) || errors.Is(err, export.ErrUnsupportedFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Failed to get sales breakdown",
+	})
+}
+
+// sendExport sends an exported report as a download named after the report
+// and its date range, e.g. sales-by-product_2026-10-01_2026-10-31.csv.
+func sendExport(c *fiber.Ctx, name string, filter model.ReportFilter, format string, contentType string, body []byte) error {
+	lastDay := filter.To.AddDate(0, 0, -1)
+	filename := fmt.Sprintf("%s_%s_%s.%s", name, filter.From.Format(period.DateLayout), lastDay.Format(period.DateLayout), format)
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.Send(body)
+}
+
+func topError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrInvalidRankBy) || errors.Is(err, service.ErrInvalidLimit) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Failed to get top report",
+	})
+}
+
+// reportTimezone reads the tz query parameter, defaulting to the store
+// timezone.
+func reportTimezone(c *fiber.Ctx, config model.ReportConfig) (*time.Location, error) {
+	loc, err := period.Location(c.Query("tz"), config.Timezone)
+	if err != nil {
+		return nil, errInvalidTimezone
+	}
+	return loc, nil
+}
+
+// reportFilter reads the tz, start_date, end_date and location_id query
+// parameters shared by the report endpoints.
+func reportFilter(c *fiber.Ctx, config model.ReportConfig) (model.ReportFilter, error) {
+	loc, err := reportTimezone(c, config)
+	if err != nil {
+		return model.ReportFilter{}, err
+	}
+	from, to, err := period.Range(c.Query("start_date"), c.Query("end_date"), loc, time.Now(), config.MaxRangeDays)
+	if err != nil {
+		return model.ReportFilter{}, err
+	}
+	return model.ReportFilter{From: from, To: to, Timezone: loc, LocationID: c.QueryInt("location_id")}, nil
+}
+
+// defaultRelatedDays is the window of sales used for related products when
+// none is given.
+const defaultRelatedDays = 90
+
+func (h *ReportHandler) Basket(c *fiber.Ctx) error {
+	filter, err := reportFilter(c, h.reportConfig)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	analysis, err := h.reportService.Basket(filter, c.QueryInt("min_transactions"), c.QueryInt("limit"))
+	if err != nil {
+		return basketError(c, err)
+	}
+	return c.JSON(analysis)
+}
+
+// Related lists the products bought together with a product over the last
+// days days, today included.
+func (h *ReportHandler) Related(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid product ID",
+		})
+	}
+	days := c.QueryInt("days", defaultRelatedDays)
+	err = checkDays("days", days, h.reportConfig.MaxRangeDays)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	loc := h.reportConfig.Timezone
+	_, to := period.Day(time.Now(), loc)
+	filter := model.ReportFilter{From: to.AddDate(0, 0, -days), To: to, Timezone: loc, LocationID: c.QueryInt("location_id")}
+
+	related, err := h.reportService.Related(id, filter, c.QueryInt("limit"))
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Product not found",
+		})
+	}
+	if err != nil {
+		return basketError(c, err)
+	}
+	return c.JSON(related)
+}
+
+// checkDays checks a number of days counted back from today against the
+// maximum report range, where 0 means no maximum.
+func checkDays(name string, days int, maxDays int) error {
+	if maxDays <= 0 {
+		if days < 1 {
+			return fmt.Errorf("%s must be at least 1", name)
+		}
+		return nil
+	}
+	if days < 1 || days > maxDays {
+		return fmt.Errorf("%s must be between 1 and %d", name, maxDays)
+	}
+	return nil
+}
+
+// Defaults of the inventory report: the days of sales averaged for the days
+// of cover, and the days without a sale after which stock counts as dead.
+const (
+	defaultInventorySalesDays = 30
+	defaultDeadStockDays      = 90
+)
+
+// Inventory values the stock on hand, with its days of cover and the dead
+// stock.
+func (h *ReportHandler) Inventory(c *fiber.Ctx) error {
+	salesDays := c.QueryInt("sales_days", defaultInventorySalesDays)
+	err := checkDays("sales_days", salesDays, h.reportConfig.MaxRangeDays)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	deadDays := c.QueryInt("dead_days", defaultDeadStockDays)
+	if deadDays < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "dead_days must be at least 1",
+		})
+	}
+	loc := h.reportConfig.Timezone
+	_, to := period.Day(time.Now(), loc)
+	filter := model.ReportFilter{From: to.AddDate(0, 0, -salesDays), To: to, Timezone: loc, LocationID: c.QueryInt("location_id")}
+
+	report, err := h.reportService.Inventory(filter, deadDays, c.Query("group"))
+	if errors.Is(err, service.ErrInvalidGroup) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get inventory report",
+		})
+	}
+	return c.JSON(report)
+}
+
+func basketError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrInvalidMinCount) || errors.Is(err, service.ErrInvalidLimit) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Failed to get basket analysis",
+	})
+}
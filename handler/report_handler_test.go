@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"product-api/model"
+	"product-api/service"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// stubReportService records the filter the handler resolved. Methods the
+// tests do not call panic through the nil embedded interface.
+type stubReportService struct {
+	service.ReportServiceInterface
+	filter *model.ReportFilter
+}
+
+func (s *stubReportService) Summary(filter model.ReportFilter, compare string) (model.SummaryResponse, error) {
+	s.filter = &filter
+	return model.SummaryResponse{}, nil
+}
+
+func newReportTestApp(t *testing.T, maxRangeDays int) (*fiber.App, *stubReportService) {
+	t.Helper()
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &stubReportService{}
+	h := NewReportHandler(stub, model.ReportConfig{Timezone: loc, MaxRangeDays: maxRangeDays})
+	app := fiber.New()
+	app.Get("/api/report", h.Summary)
+	app.Get("/api/product/:id/related", h.Related)
+	app.Get("/api/report/inventory", h.Inventory)
+	return app, stub
+}
+
+func getReport(t *testing.T, app *fiber.App, query string) (int, string) {
+	t.Helper()
+	return get(t, app, "/api/report"+query)
+}
+
+// get returns the status and the message of a JSON response.
+func get(t *testing.T, app *fiber.App, path string) (int, string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(body, &payload)
+	return resp.StatusCode, payload.Message
+}
+
+func TestReportFilterDefaultRange(t *testing.T) {
+	app, stub := newReportTestApp(t, 366)
+	status, message := getReport(t, app, "")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, message)
+	}
+
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	wantFrom := today.AddDate(0, 0, -6)
+	wantTo := today.AddDate(0, 0, 1)
+	if !stub.filter.From.Equal(wantFrom) || !stub.filter.To.Equal(wantTo) {
+		t.Errorf("range = [%s, %s), want [%s, %s)", stub.filter.From, stub.filter.To, wantFrom, wantTo)
+	}
+	if stub.filter.Timezone.String() != "Asia/Jakarta" {
+		t.Errorf("timezone = %s, want the store timezone", stub.filter.Timezone)
+	}
+}
+
+func TestReportFilterExplicitRange(t *testing.T) {
+	app, stub := newReportTestApp(t, 31)
+	status, message := getReport(t, app, "?start_date=2026-10-01&end_date=2026-10-31&tz=UTC&location_id=2")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", status, message)
+	}
+
+	wantFrom := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	wantTo := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	if !stub.filter.From.Equal(wantFrom) || !stub.filter.To.Equal(wantTo) {
+		t.Errorf("range = [%s, %s), want [%s, %s)", stub.filter.From, stub.filter.To, wantFrom, wantTo)
+	}
+	if stub.filter.LocationID != 2 {
+		t.Errorf("location_id = %d, want 2", stub.filter.LocationID)
+	}
+}
+
+func TestReportFilterRejectsInvalidParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{"malformed start_date", "?start_date=2026-13-01", "start_date must be a valid date in YYYY-MM-DD format"},
+		{"malformed end_date", "?end_date=31-10-2026", "end_date must be a valid date in YYYY-MM-DD format"},
+		{"end before start", "?start_date=2026-10-31&end_date=2026-10-01", "end_date must not be before start_date"},
+		{"range over max", "?start_date=2026-10-01&end_date=2026-11-01", "date range must not exceed 31 days"},
+		{"invalid tz", "?tz=Mars/Olympus_Mons", errInvalidTimezone.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, stub := newReportTestApp(t, 31)
+			status, message := getReport(t, app, tt.query)
+			if status != fiber.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", status)
+			}
+			if message != tt.message {
+				t.Errorf("message = %q, want %q", message, tt.message)
+			}
+			if stub.filter != nil {
+				t.Error("report service called with an invalid filter")
+			}
+		})
+	}
+}
+
+func TestRelatedDaysMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRangeDays int
+		query        string
+		message      string
+	}{
+		{"below minimum with maximum", 31, "?days=0", "days must be between 1 and 31"},
+		{"above maximum", 31, "?days=32", "days must be between 1 and 31"},
+		{"below minimum without maximum", 0, "?days=0", "days must be at least 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newReportTestApp(t, tt.maxRangeDays)
+			status, message := get(t, app, "/api/product/1/related"+tt.query)
+			if status != fiber.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", status)
+			}
+			if message != tt.message {
+				t.Errorf("message = %q, want %q", message, tt.message)
+			}
+		})
+	}
+}
+
+func TestInventorySalesDaysMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRangeDays int
+		query        string
+		message      string
+	}{
+		{"below minimum with maximum", 31, "?sales_days=0", "sales_days must be between 1 and 31"},
+		{"above maximum", 31, "?sales_days=32", "sales_days must be between 1 and 31"},
+		{"below minimum without maximum", 0, "?sales_days=0", "sales_days must be at least 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newReportTestApp(t, tt.maxRangeDays)
+			status, message := get(t, app, "/api/report/inventory"+tt.query)
+			if status != fiber.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", status)
+			}
+			if message != tt.message {
+				t.Errorf("message = %q, want %q", message, tt.message)
+			}
+		})
+	}
+}
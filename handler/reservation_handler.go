@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReservationHandler struct {
+	reservationService service.ReservationServiceInterface
+}
+
+func NewReservationHandler(reservationService service.ReservationServiceInterface) *ReservationHandler {
+	return &ReservationHandler{reservationService: reservationService}
+}
+
+func (h *ReservationHandler) Create(c *fiber.Ctx) error {
+	var request model.CreateReservationRequest
+	err := c.BodyParser(&request)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	reservation, err := h.reservationService.Create(&request)
+	if errors.Is(err, repository.ErrReservationExists) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(reservation)
+}
+
+func (h *ReservationHandler) GetByReference(c *fiber.Ctx) error {
+	reservation, err := h.reservationService.GetByReference(c.Params("reference"))
+	if errors.Is(err, repository.ErrReservationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get reservation",
+		})
+	}
+	return c.JSON(reservation)
+}
+
+func (h *ReservationHandler) Release(c *fiber.Ctx) error {
+	err := h.reservationService.Release(c.Params("reference"))
+	if errors.Is(err, repository.ErrReservationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to release reservation",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": "Reservation released successfully",
+	})
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ShiftHandler struct {
+	shiftService service.ShiftServiceInterface
+}
+
+func NewShiftHandler(shiftService service.ShiftServiceInterface) *ShiftHandler {
+	return &ShiftHandler{shiftService: shiftService}
+}
+
+func (h *ShiftHandler) GetAll(c *fiber.Ctx) error {
+	shifts, err := h.shiftService.GetAll(c.Query("cashier"), c.Query("status"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(shifts)
+}
+
+func (h *ShiftHandler) Open(c *fiber.Ctx) error {
+	var shift model.Shift
+	err := c.BodyParser(&shift)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	err = h.shiftService.Open(&shift)
+	if errors.Is(err, repository.ErrShiftAlreadyOpen) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(shift)
+}
+
+func (h *ShiftHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid shift ID",
+		})
+	}
+	shift, err := h.shiftService.GetByID(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Shift not found",
+		})
+	}
+	return c.JSON(shift)
+}
+
+func (h *ShiftHandler) AddCashEvent(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid shift ID",
+		})
+	}
+	var event model.CashEvent
+	err = c.BodyParser(&event)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+	event.ShiftID = id
+
+	err = h.shiftService.AddCashEvent(&event)
+	if errors.Is(err, repository.ErrShiftNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Shift not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(event)
+}
+
+func (h *ShiftHandler) Close(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid shift ID",
+		})
+	}
+	var request model.CloseShiftRequest
+	err = c.BodyParser(&request)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	report, err := h.shiftService.Close(id, &request)
+	if errors.Is(err, repository.ErrShiftNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Shift not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(report)
+}
+
+func (h *ShiftHandler) Report(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid shift ID",
+		})
+	}
+	report, err := h.shiftService.Report(id)
+	if errors.Is(err, repository.ErrShiftNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Shift not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get shift report",
+		})
+	}
+	return c.JSON(report)
+}
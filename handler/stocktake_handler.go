@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type StocktakeHandler struct {
+	stocktakeService service.StocktakeServiceInterface
+}
+
+func NewStocktakeHandler(stocktakeService service.StocktakeServiceInterface) *StocktakeHandler {
+	return &StocktakeHandler{stocktakeService: stocktakeService}
+}
+
+func (h *StocktakeHandler) GetAll(c *fiber.Ctx) error {
+	stocktakes, err := h.stocktakeService.GetAll(c.Query("status"), c.QueryInt("location_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(stocktakes)
+}
+
+func (h *StocktakeHandler) Start(c *fiber.Ctx) error {
+	var stocktake model.Stocktake
+	if len(c.Body()) > 0 {
+		err := c.BodyParser(&stocktake)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid request body",
+			})
+		}
+	}
+
+	err := h.stocktakeService.Start(&stocktake)
+	if errors.Is(err, repository.ErrLocationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Location not found",
+		})
+	}
+	if errors.Is(err, repository.ErrStocktakeInProgress) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to start stocktake",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(stocktake)
+}
+
+func (h *StocktakeHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid stocktake ID",
+		})
+	}
+	stocktake, err := h.stocktakeService.GetByID(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Stocktake not found",
+		})
+	}
+	return c.JSON(stocktake)
+}
+
+func (h *StocktakeHandler) AddCounts(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid stocktake ID",
+		})
+	}
+	var request model.StocktakeCountRequest
+	err = c.BodyParser(&request)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	report, err := h.stocktakeService.AddCounts(id, &request)
+	if err != nil {
+		return stocktakeError(c, err)
+	}
+	return c.JSON(report)
+}
+
+func (h *StocktakeHandler) Variance(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid stocktake ID",
+		})
+	}
+	report, err := h.stocktakeService.Variance(id)
+	if err != nil {
+		return stocktakeError(c, err)
+	}
+	return c.JSON(report)
+}
+
+func (h *StocktakeHandler) Approve(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid stocktake ID",
+		})
+	}
+	var request model.ApproveStocktakeRequest
+	if len(c.Body()) > 0 {
+		err = c.BodyParser(&request)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid request body",
+			})
+		}
+	}
+
+	report, err := h.stocktakeService.Approve(id, &request)
+	if err != nil {
+		return stocktakeError(c, err)
+	}
+	return c.JSON(report)
+}
+
+func (h *StocktakeHandler) Cancel(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid stocktake ID",
+		})
+	}
+	stocktake, err := h.stocktakeService.Cancel(id)
+	if err != nil {
+		return stocktakeError(c, err)
+	}
+	return c.JSON(stocktake)
+}
+
+func stocktakeError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	switch {
+	case errors.Is(err, repository.ErrStocktakeNotFound), errors.Is(err, repository.ErrProductNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, repository.ErrStocktakeNotCounting):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"message": err.Error(),
+	})
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"product-api/model"
+	"product-api/repository"
 	"product-api/service"
-	"time"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -26,6 +28,11 @@ func (h *TransactionHandler) Create(c *fiber.Ctx) error {
 	}
 
 	transaction, err := h.transactionService.Checkout(&request)
+	if errors.Is(err, repository.ErrDayClosed) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": err.Error(),
@@ -34,27 +41,83 @@ func (h *TransactionHandler) Create(c *fiber.Ctx) error {
 	return c.JSON(transaction)
 }
 
-func (h *TransactionHandler) Summary(c *fiber.Ctx) error {
-	timeNow := time.Now()
-	fromDate := timeNow.Format("2006-01-02") + " 00:00:00"
-	toDate := timeNow.Format("2006-01-02") + " 23:59:59"
-	summary, err := h.transactionService.Summary(fromDate, toDate)
+func (h *TransactionHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid transaction ID",
+		})
+	}
+
+	transaction, err := h.transactionService.GetByID(id)
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Transaction not found",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Failed to get summary",
+			"message": "Failed to get transaction",
 		})
 	}
-	return c.JSON(summary)
+	return c.JSON(transaction)
 }
 
-func (h *TransactionHandler) SummaryByDate(c *fiber.Ctx) error {
-	fromDate := c.Query("start_date") + " 00:00:00"
-	toDate := c.Query("end_date") + " 23:59:59"
-	summary, err := h.transactionService.Summary(fromDate, toDate)
+func (h *TransactionHandler) GetByInvoiceNumber(c *fiber.Ctx) error {
+	invoiceNumber := c.Params("*")
+	if invoiceNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid invoice number",
+		})
+	}
+
+	transaction, err := h.transactionService.GetByInvoiceNumber(invoiceNumber)
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Transaction not found",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Failed to get summary",
+			"message": "Failed to get transaction",
+		})
+	}
+	return c.JSON(transaction)
+}
+
+func (h *TransactionHandler) Refund(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid transaction ID",
+		})
+	}
+
+	var request model.RefundRequest
+	if len(c.Body()) > 0 {
+		err = c.BodyParser(&request)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid request body",
+			})
+		}
+	}
+
+	transaction, err := h.transactionService.Refund(id, &request)
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Transaction not found",
 		})
 	}
-	return c.JSON(summary)
+	if errors.Is(err, repository.ErrDayClosed) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(transaction)
 }
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TransferHandler struct {
+	transferService service.TransferServiceInterface
+}
+
+func NewTransferHandler(transferService service.TransferServiceInterface) *TransferHandler {
+	return &TransferHandler{transferService: transferService}
+}
+
+func (h *TransferHandler) GetAll(c *fiber.Ctx) error {
+	transfers, err := h.transferService.GetAll(c.Query("status"), c.QueryInt("location_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(transfers)
+}
+
+func (h *TransferHandler) Create(c *fiber.Ctx) error {
+	var transfer model.StockTransfer
+	err := c.BodyParser(&transfer)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	err = h.transferService.Create(&transfer)
+	if errors.Is(err, repository.ErrLocationNotFound) || errors.Is(err, repository.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(transfer)
+}
+
+func (h *TransferHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid transfer ID",
+		})
+	}
+	transfer, err := h.transferService.GetByID(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Transfer not found",
+		})
+	}
+	return c.JSON(transfer)
+}
+
+func (h *TransferHandler) Receive(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid transfer ID",
+		})
+	}
+	transfer, err := h.transferService.Receive(id)
+	if err != nil {
+		return transferError(c, err)
+	}
+	return c.JSON(transfer)
+}
+
+func (h *TransferHandler) Cancel(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid transfer ID",
+		})
+	}
+	transfer, err := h.transferService.Cancel(id)
+	if err != nil {
+		return transferError(c, err)
+	}
+	return c.JSON(transfer)
+}
+
+func transferError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, repository.ErrTransferNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Transfer not found",
+		})
+	}
+	if errors.Is(err, repository.ErrTransferNotInTransit) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Failed to update transfer",
+	})
+}
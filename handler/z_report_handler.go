@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ZReportHandler struct {
+	zReportService service.ZReportServiceInterface
+}
+
+func NewZReportHandler(zReportService service.ZReportServiceInterface) *ZReportHandler {
+	return &ZReportHandler{zReportService: zReportService}
+}
+
+func (h *ZReportHandler) CloseDay(c *fiber.Ctx) error {
+	var request model.CloseDayRequest
+	err := c.BodyParser(&request)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	report, err := h.zReportService.CloseDay(&request)
+	if errors.Is(err, repository.ErrLocationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Location not found",
+		})
+	}
+	if errors.Is(err, repository.ErrDayClosed) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(report)
+}
+
+func (h *ZReportHandler) GetAll(c *fiber.Ctx) error {
+	reports, err := h.zReportService.GetAll(c.QueryInt("location_id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get Z-reports",
+		})
+	}
+	return c.JSON(reports)
+}
+
+func (h *ZReportHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid Z-report ID",
+		})
+	}
+	report, err := h.zReportService.GetByID(id)
+	if errors.Is(err, repository.ErrZReportNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Z-report not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get Z-report",
+		})
+	}
+	return c.JSON(report)
+}
+
+func (h *ZReportHandler) Print(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid Z-report ID",
+		})
+	}
+	format := c.Query("format", model.ReceiptFormatText)
+	paper := c.QueryInt("paper", 80)
+
+	body, contentType, err := h.zReportService.Print(id, format, paper)
+	if errors.Is(err, repository.ErrZReportNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Z-report not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(body)
+}
@@ -1,25 +1,46 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 	"product-api/model"
 	"product-api/utils/database"
+	"product-api/utils/invoice"
+	"product-api/utils/period"
 	"strings"
 	"time"
+	_ "time/tzdata"
 
 	"product-api/handler"
 	"product-api/repository"
 	"product-api/service"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/spf13/viper"
 )
 
 func main() {
+	rebuildRollups := flag.Bool("rebuild-rollups", false, "recompute the daily sales rollups from all transactions and exit")
+	flag.Parse()
+
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	viper.SetDefault("STORE_CODE", "MAIN")
+	viper.SetDefault("DEFAULT_LOCATION", "MAIN")
+	viper.SetDefault("STORE_TIMEZONE", "Asia/Jakarta")
+	viper.SetDefault("REPORT_MAX_RANGE_DAYS", 366)
+	viper.SetDefault("INVOICE_FORMAT", invoice.DefaultFormat)
+	viper.SetDefault("TAX_RATE", 0)
+	viper.SetDefault("LOYALTY_EARN_AMOUNT", 10000)
+	viper.SetDefault("LOYALTY_REDEEM_VALUE", 100)
+	viper.SetDefault("LOYALTY_EXPIRY_DAYS", 365)
+	viper.SetDefault("CART_RESERVATION_MINUTES", 15)
+	viper.SetDefault("RESERVATION_TTL_MINUTES", 30)
+	viper.SetDefault("RESERVATION_SWEEP_SECONDS", 60)
+
 	if _, err := os.Stat(".env"); err == nil {
 		viper.SetConfigFile(".env")
 		_ = viper.ReadInConfig()
@@ -27,6 +48,37 @@ func main() {
 	config := model.Config{
 		Port:   viper.GetString("PORT"),
 		DBConn: viper.GetString("DB_CONN"),
+
+		StoreCode:     viper.GetString("STORE_CODE"),
+		InvoiceFormat: viper.GetString("INVOICE_FORMAT"),
+		TaxRate:       viper.GetFloat64("TAX_RATE"),
+
+		DefaultLocation: viper.GetString("DEFAULT_LOCATION"),
+		StoreTimezone:   viper.GetString("STORE_TIMEZONE"),
+
+		ReportMaxRangeDays: viper.GetInt("REPORT_MAX_RANGE_DAYS"),
+
+		AllowedOrigins: viper.GetString("ALLOWED_ORIGINS"),
+
+		LoyaltyEarnAmount:  viper.GetInt("LOYALTY_EARN_AMOUNT"),
+		LoyaltyRedeemValue: viper.GetInt("LOYALTY_REDEEM_VALUE"),
+		LoyaltyExpiryDays:  viper.GetInt("LOYALTY_EXPIRY_DAYS"),
+
+		CartReservationMinutes:  viper.GetInt("CART_RESERVATION_MINUTES"),
+		ReservationTTLMinutes:   viper.GetInt("RESERVATION_TTL_MINUTES"),
+		ReservationSweepSeconds: viper.GetInt("RESERVATION_SWEEP_SECONDS"),
+	}
+
+	if err := invoice.Validate(config.InvoiceFormat); err != nil {
+		log.Fatalf("Invalid INVOICE_FORMAT: %v", err)
+	}
+	storeTimezone, err := period.Location(config.StoreTimezone, time.UTC)
+	if err != nil {
+		log.Fatalf("Invalid STORE_TIMEZONE: %v", err)
+	}
+	reportConfig := model.ReportConfig{
+		Timezone:     storeTimezone,
+		MaxRangeDays: config.ReportMaxRangeDays,
 	}
 
 	db, err := database.InitDB(config.DBConn)
@@ -35,6 +87,16 @@ func main() {
 	}
 	defer db.Close()
 
+	rollupRepo := repository.NewRollupRepository(db, storeTimezone)
+	if *rebuildRollups {
+		err = rollupRepo.Rebuild()
+		if err != nil {
+			log.Fatalf("Failed to rebuild rollups: %v", err)
+		}
+		log.Println("Daily sales rollups rebuilt")
+		return
+	}
+
 	app := fiber.New()
 	app.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -49,29 +111,181 @@ func main() {
 	categoryService := service.NewCategoryService(categoryRepo)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
+	locationRepo := repository.NewLocationRepository(db)
+	stockRepo := repository.NewStockRepository(db)
+
 	productRepo := repository.NewProductRepository(db)
-	productService := service.NewProductService(productRepo, categoryRepo)
+	productPriceRepo := repository.NewProductPriceRepository(db)
+	productService := service.NewProductService(productRepo, categoryRepo, productPriceRepo, stockRepo, locationRepo, config.DefaultLocation)
 	productHandler := handler.NewProductHandler(productService)
 
 	transactionRepo := repository.NewTransactionRepository(db)
-	transactionService := service.NewTransactionService(transactionRepo, productRepo)
+	customerRepo := repository.NewCustomerRepository(db)
+	loyaltyRepo := repository.NewLoyaltyRepository(db)
+	loyaltyService := service.NewLoyaltyService(loyaltyRepo, model.LoyaltyRule{
+		EarnAmount:  config.LoyaltyEarnAmount,
+		RedeemValue: config.LoyaltyRedeemValue,
+		ExpiryDays:  config.LoyaltyExpiryDays,
+	})
+	invoiceRepo := repository.NewInvoiceRepository(db)
+	shiftRepo := repository.NewShiftRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
+	batchRepo := repository.NewBatchRepository(db)
+	batchService := service.NewBatchService(batchRepo, stockRepo, productRepo, locationRepo, config.DefaultLocation, storeTimezone)
+	batchHandler := handler.NewBatchHandler(batchService)
+	zReportRepo := repository.NewZReportRepository(db)
+	reportRepo := repository.NewReportRepository(db, storeTimezone)
+	salesFeed := service.NewSalesFeed(reportRepo, storeTimezone)
+	go salesFeed.Run()
+	eventHandler := handler.NewEventHandler(salesFeed, allowedOrigins(config.AllowedOrigins))
+	transactionService := service.NewTransactionService(transactionRepo, productRepo, customerRepo, invoiceRepo, shiftRepo, reservationRepo, stockRepo, locationRepo, loyaltyService, batchService, rollupRepo, zReportRepo, salesFeed, model.CheckoutConfig{
+		StoreCode:       config.StoreCode,
+		InvoiceFormat:   config.InvoiceFormat,
+		TaxRate:         config.TaxRate,
+		DefaultLocation: config.DefaultLocation,
+		Timezone:        storeTimezone,
+	})
 	transactionHandler := handler.NewTransactionHandler(transactionService)
 
+	reservationService := service.NewReservationService(reservationRepo, productRepo, time.Duration(config.ReservationTTLMinutes)*time.Minute)
+	reservationHandler := handler.NewReservationHandler(reservationService)
+	if config.ReservationSweepSeconds > 0 {
+		go reservationService.RunSweeper(time.Duration(config.ReservationSweepSeconds) * time.Second)
+	}
+
+	cartRepo := repository.NewCartRepository(db)
+	cartService := service.NewCartService(cartRepo, reservationRepo, productRepo, customerRepo, transactionService, salesFeed, time.Duration(config.CartReservationMinutes)*time.Minute)
+	cartHandler := handler.NewCartHandler(cartService)
+
+	shiftService := service.NewShiftService(shiftRepo, locationRepo, config.DefaultLocation)
+	shiftHandler := handler.NewShiftHandler(shiftService)
+
+	locationService := service.NewLocationService(locationRepo, stockRepo, productRepo)
+	locationHandler := handler.NewLocationHandler(locationService)
+
+	transferRepo := repository.NewTransferRepository(db)
+	transferService := service.NewTransferService(transferRepo, locationRepo, stockRepo, productRepo, reservationRepo, batchRepo, batchService)
+	transferHandler := handler.NewTransferHandler(transferService)
+
+	stocktakeRepo := repository.NewStocktakeRepository(db)
+	stocktakeService := service.NewStocktakeService(stocktakeRepo, stockRepo, productRepo, locationRepo, config.DefaultLocation)
+	stocktakeHandler := handler.NewStocktakeHandler(stocktakeService)
+
+	receiptRepo := repository.NewReceiptRepository(db)
+	receiptService := service.NewReceiptService(receiptRepo, transactionRepo, storeTimezone)
+	receiptHandler := handler.NewReceiptHandler(receiptService)
+
+	reportService := service.NewReportService(reportRepo, transactionRepo, receiptRepo, productRepo)
+	reportHandler := handler.NewReportHandler(reportService, reportConfig)
+	zReportService := service.NewZReportService(zReportRepo, locationRepo, receiptRepo, config.DefaultLocation, storeTimezone)
+	zReportHandler := handler.NewZReportHandler(zReportService)
+
+	customerService := service.NewCustomerService(customerRepo, transactionRepo, loyaltyService)
+	customerHandler := handler.NewCustomerHandler(customerService)
+
 	app.Get("/api/category", categoryHandler.GetAll)
 	app.Get("/api/category/:id", categoryHandler.GetByID)
 	app.Post("/api/category", categoryHandler.Create)
 	app.Put("/api/category/:id", categoryHandler.Update)
 	app.Delete("/api/category/:id", categoryHandler.Delete)
+	app.Post("/api/category/:id/restore", categoryHandler.Restore)
 
 	app.Get("/api/product", productHandler.HandleProducts)
 	app.Get("/api/product/:id", productHandler.GetByID)
 	app.Post("/api/product", productHandler.Create)
 	app.Put("/api/product/:id", productHandler.Update)
 	app.Delete("/api/product/:id", productHandler.Delete)
+	app.Post("/api/product/:id/restore", productHandler.Restore)
+	app.Get("/api/product/:id/prices", productHandler.GetPrices)
+	app.Post("/api/product/:id/prices", productHandler.SchedulePrice)
+	app.Delete("/api/product/:id/prices/:price_id", productHandler.CancelScheduledPrice)
+	app.Get("/api/product/:id/stock", productHandler.GetStock)
+	app.Get("/api/product/:id/batches", batchHandler.GetByProduct)
+	app.Get("/api/product/:id/related", reportHandler.Related)
+
+	app.Get("/api/goods-receipts", batchHandler.GetReceipts)
+	app.Post("/api/goods-receipts", batchHandler.ReceiveGoods)
+	app.Get("/api/goods-receipts/:id", batchHandler.GetReceiptByID)
+
+	app.Get("/api/locations", locationHandler.GetAll)
+	app.Post("/api/locations", locationHandler.Create)
+	app.Get("/api/locations/:id", locationHandler.GetByID)
+	app.Put("/api/locations/:id", locationHandler.Update)
+	app.Get("/api/locations/:id/stock", locationHandler.GetStock)
+	app.Put("/api/locations/:id/stock/:product_id", locationHandler.SetStock)
+
+	app.Get("/api/transfers", transferHandler.GetAll)
+	app.Post("/api/transfers", transferHandler.Create)
+	app.Get("/api/transfers/:id", transferHandler.GetByID)
+	app.Post("/api/transfers/:id/receive", transferHandler.Receive)
+	app.Post("/api/transfers/:id/cancel", transferHandler.Cancel)
+
+	app.Get("/api/stocktakes", stocktakeHandler.GetAll)
+	app.Post("/api/stocktakes", stocktakeHandler.Start)
+	app.Get("/api/stocktakes/:id", stocktakeHandler.GetByID)
+	app.Post("/api/stocktakes/:id/counts", stocktakeHandler.AddCounts)
+	app.Get("/api/stocktakes/:id/variance", stocktakeHandler.Variance)
+	app.Post("/api/stocktakes/:id/approve", stocktakeHandler.Approve)
+	app.Post("/api/stocktakes/:id/cancel", stocktakeHandler.Cancel)
+
+	app.Get("/api/customers", customerHandler.GetAll)
+	app.Get("/api/customers/:id", customerHandler.GetByID)
+	app.Post("/api/customers", customerHandler.Create)
+	app.Put("/api/customers/:id", customerHandler.Update)
+	app.Delete("/api/customers/:id", customerHandler.Delete)
+	app.Get("/api/customers/:id/transactions", customerHandler.GetTransactions)
+	app.Get("/api/customers/:id/points", customerHandler.GetPoints)
+
+	app.Get("/api/shifts", shiftHandler.GetAll)
+	app.Post("/api/shifts", shiftHandler.Open)
+	app.Get("/api/shifts/:id", shiftHandler.GetByID)
+	app.Post("/api/shifts/:id/cash", shiftHandler.AddCashEvent)
+	app.Post("/api/shifts/:id/close", shiftHandler.Close)
+	app.Get("/api/shifts/:id/report", shiftHandler.Report)
+
+	app.Post("/api/reservations", reservationHandler.Create)
+	app.Get("/api/reservations/:reference", reservationHandler.GetByReference)
+	app.Delete("/api/reservations/:reference", reservationHandler.Release)
+
+	app.Get("/api/carts", cartHandler.GetAll)
+	app.Post("/api/carts", cartHandler.Create)
+	app.Get("/api/carts/:id", cartHandler.GetByID)
+	app.Delete("/api/carts/:id", cartHandler.Discard)
+	app.Post("/api/carts/:id/items", cartHandler.AddItem)
+	app.Put("/api/carts/:id/items/:product_id", cartHandler.UpdateItem)
+	app.Delete("/api/carts/:id/items/:product_id", cartHandler.RemoveItem)
+	app.Post("/api/carts/:id/park", cartHandler.Park)
+	app.Post("/api/carts/:id/resume", cartHandler.Resume)
+	app.Post("/api/carts/:id/checkout", cartHandler.Checkout)
 
 	app.Post("/api/checkout", transactionHandler.Create)
-	app.Get("/api/report/hari-ini", transactionHandler.Summary)
-	app.Get("/api/report", transactionHandler.SummaryByDate)
+	app.Get("/api/transactions/invoice/*", transactionHandler.GetByInvoiceNumber)
+	app.Get("/api/transactions/:id", transactionHandler.GetByID)
+	app.Post("/api/transactions/:id/refund", transactionHandler.Refund)
+	app.Get("/api/transactions/:id/receipt", receiptHandler.Render)
+
+	app.Get("/api/receipt/settings", receiptHandler.GetSettings)
+	app.Put("/api/receipt/settings", receiptHandler.UpdateSettings)
+	app.Get("/api/receipt/templates/:format", receiptHandler.GetTemplate)
+	app.Put("/api/receipt/templates/:format", receiptHandler.UpdateTemplate)
+	app.Delete("/api/receipt/templates/:format", receiptHandler.ResetTemplate)
+	app.Get("/api/report/hari-ini", reportHandler.Today)
+	app.Get("/api/report", reportHandler.Summary)
+	app.Get("/api/report/transactions", reportHandler.Transactions)
+	app.Get("/api/report/expiring-batches", batchHandler.Expiring)
+	app.Get("/api/report/timeseries", reportHandler.Timeseries)
+	app.Get("/api/report/top-products", reportHandler.TopProducts)
+	app.Get("/api/report/top-categories", reportHandler.TopCategories)
+	app.Get("/api/report/breakdown", reportHandler.Breakdown)
+	app.Get("/api/report/basket", reportHandler.Basket)
+	app.Get("/api/report/inventory", reportHandler.Inventory)
+	app.Post("/api/report/close-day", zReportHandler.CloseDay)
+	app.Get("/api/report/z-reports", zReportHandler.GetAll)
+	app.Get("/api/report/z-reports/:id", zReportHandler.GetByID)
+	app.Get("/api/report/z-reports/:id/print", zReportHandler.Print)
+
+	app.Get("/api/events/sales", eventHandler.Sales)
+	app.Get("/api/events/sales/ws", eventHandler.SalesWebSocketUpgrade, websocket.New(eventHandler.SalesWebSocket))
 
 	err = app.Listen(":" + config.Port)
 	if err != nil {
@@ -80,3 +294,15 @@ func main() {
 
 	log.Println("Server started on port", config.Port)
 }
+
+// allowedOrigins splits the comma-separated ALLOWED_ORIGINS value.
+func allowedOrigins(value string) []string {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin != "" {
+			origins = append(origins, strings.TrimSuffix(origin, "/"))
+		}
+	}
+	return origins
+}
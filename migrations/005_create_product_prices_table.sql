@@ -0,0 +1,21 @@
+-- Create product_prices table to keep price history and scheduled prices
+CREATE TABLE IF NOT EXISTS product_prices (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id),
+    price INT NOT NULL,
+    effective_from TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index to find the price effective at a given time
+CREATE INDEX IF NOT EXISTS idx_product_prices_product_effective ON product_prices(product_id, effective_from DESC);
+
+-- Seed history with the current price of existing products
+INSERT INTO product_prices (product_id, price, effective_from)
+SELECT p.id, p.price, COALESCE(p.created_at, CURRENT_TIMESTAMP)
+FROM products p
+WHERE NOT EXISTS (SELECT 1 FROM product_prices pp WHERE pp.product_id = p.id);
+
+-- Keep the unit price used at sale time on every transaction detail
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS price INT;
+UPDATE transaction_details SET price = subtotal / NULLIF(quantity, 0) WHERE price IS NULL;
@@ -0,0 +1,18 @@
+-- Create loyalty_points ledger; the balance of a customer is the sum of points
+CREATE TABLE IF NOT EXISTS loyalty_points (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL REFERENCES customers(id),
+    transaction_id INT REFERENCES transactions(id),
+    type VARCHAR(10) NOT NULL CHECK (type IN ('earn', 'redeem', 'expire', 'refund')),
+    points INT NOT NULL,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_loyalty_points_customer_id ON loyalty_points(customer_id);
+CREATE INDEX IF NOT EXISTS idx_loyalty_points_transaction_id ON loyalty_points(transaction_id);
+
+-- Discount from redeemed points and refund marker on transactions
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS discount_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMPTZ;
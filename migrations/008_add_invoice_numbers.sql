@@ -0,0 +1,38 @@
+-- Gap-free invoice sequences, reset per store and per month
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    store_code VARCHAR(20) NOT NULL,
+    period CHAR(7) NOT NULL,
+    last_number INT NOT NULL,
+    PRIMARY KEY (store_code, period)
+);
+
+-- Store the invoice number on transactions
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS store_code VARCHAR(20);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS invoice_number VARCHAR(50);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_invoice_number ON transactions(invoice_number);
+
+-- Number existing transactions the way the default format numbers them for
+-- store MAIN. Months follow STORE_TIMEZONE like invoice.Period does: created_at
+-- still holds naive session time here, so it is read in the session timezone
+-- and converted to Asia/Jakarta, the default STORE_TIMEZONE. Replace the zone
+-- below before running this if the store uses another STORE_TIMEZONE.
+WITH numbered AS (
+    SELECT id, created_at, to_char((created_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'Asia/Jakarta', 'YYYY-MM') AS period
+    FROM transactions
+    WHERE invoice_number IS NULL
+), sequenced AS (
+    SELECT id, period, ROW_NUMBER() OVER (PARTITION BY period ORDER BY created_at, id) AS seq
+    FROM numbered
+)
+UPDATE transactions t
+SET store_code = 'MAIN',
+    invoice_number = 'INV/MAIN/' || replace(s.period, '-', '/') || '/' || lpad(s.seq::text, 6, '0')
+FROM sequenced s
+WHERE t.id = s.id;
+
+INSERT INTO invoice_sequences (store_code, period, last_number)
+SELECT store_code, to_char((created_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'Asia/Jakarta', 'YYYY-MM') AS period, COUNT(*)
+FROM transactions
+WHERE store_code IS NOT NULL
+GROUP BY store_code, period
+ON CONFLICT (store_code, period) DO NOTHING;
@@ -0,0 +1,41 @@
+-- Tax, payment and change amounts on transactions
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS paid_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS change_amount INT NOT NULL DEFAULT 0;
+UPDATE transactions SET paid_amount = total_amount WHERE paid_amount = 0;
+
+-- Create transaction_payments table, one row per tender used at checkout
+CREATE TABLE IF NOT EXISTS transaction_payments (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    method VARCHAR(20) NOT NULL,
+    amount INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_payments_transaction_id ON transaction_payments(transaction_id);
+
+-- Existing transactions are assumed to be paid in cash
+INSERT INTO transaction_payments (transaction_id, method, amount)
+SELECT t.id, 'cash', t.total_amount
+FROM transactions t
+WHERE NOT EXISTS (SELECT 1 FROM transaction_payments tp WHERE tp.transaction_id = t.id);
+
+-- Store information printed on receipts (single row)
+CREATE TABLE IF NOT EXISTS receipt_settings (
+    id INT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    store_name VARCHAR(255) NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    footer TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO receipt_settings (id, store_name, footer) VALUES (1, 'Product API Store', 'Terima kasih atas kunjungan Anda')
+ON CONFLICT (id) DO NOTHING;
+
+-- Receipt templates editable at runtime, keyed by format (text, html)
+CREATE TABLE IF NOT EXISTS receipt_templates (
+    format VARCHAR(10) PRIMARY KEY,
+    content TEXT NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
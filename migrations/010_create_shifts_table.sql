@@ -0,0 +1,30 @@
+-- Create shifts table, a cashier can only have one open shift at a time
+CREATE TABLE IF NOT EXISTS shifts (
+    id SERIAL PRIMARY KEY,
+    cashier VARCHAR(100) NOT NULL,
+    opening_float INT NOT NULL DEFAULT 0,
+    opened_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMPTZ,
+    expected_cash INT,
+    counted_cash INT,
+    note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_open_cashier ON shifts(cashier) WHERE closed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_shifts_opened_at ON shifts(opened_at);
+
+-- Cash put into or taken out of the drawer outside of sales
+CREATE TABLE IF NOT EXISTS shift_cash_events (
+    id SERIAL PRIMARY KEY,
+    shift_id INT NOT NULL REFERENCES shifts(id),
+    type VARCHAR(3) NOT NULL CHECK (type IN ('in', 'out')),
+    amount INT NOT NULL CHECK (amount > 0),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_cash_events_shift_id ON shift_cash_events(shift_id);
+
+-- Link every checkout to the shift it was made in
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS shift_id INT REFERENCES shifts(id);
+CREATE INDEX IF NOT EXISTS idx_transactions_shift_id ON transactions(shift_id);
@@ -0,0 +1,6 @@
+-- Stock reservations can also be held by an order reference (e.g. a web
+-- store order) instead of a cart
+ALTER TABLE stock_reservations ADD COLUMN IF NOT EXISTS reference VARCHAR(100);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_reservations_reference_product ON stock_reservations(reference, product_id) WHERE reference IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_expires_at ON stock_reservations(expires_at);
@@ -0,0 +1,59 @@
+-- Create locations table for outlets and warehouses
+CREATE TABLE IF NOT EXISTS locations (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL DEFAULT 'outlet' CHECK (type IN ('outlet', 'warehouse')),
+    address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Existing stock is assumed to be at the main store
+INSERT INTO locations (code, name, type) VALUES ('MAIN', 'Toko Utama', 'outlet') ON CONFLICT (code) DO NOTHING;
+
+-- Stock on hand per location, products.stock holds the total over all locations
+CREATE TABLE IF NOT EXISTS stock_levels (
+    location_id INT NOT NULL REFERENCES locations(id),
+    product_id INT NOT NULL REFERENCES products(id),
+    quantity INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (location_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_levels_product_id ON stock_levels(product_id);
+
+INSERT INTO stock_levels (location_id, product_id, quantity)
+SELECT l.id, p.id, p.stock FROM products p CROSS JOIN locations l WHERE l.code = 'MAIN'
+ON CONFLICT (location_id, product_id) DO NOTHING;
+
+-- Stock transfers between locations, stock is off the books while in transit
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id SERIAL PRIMARY KEY,
+    from_location_id INT NOT NULL REFERENCES locations(id),
+    to_location_id INT NOT NULL REFERENCES locations(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'in_transit' CHECK (status IN ('in_transit', 'received', 'cancelled')),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ,
+    CHECK (from_location_id <> to_location_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_status ON stock_transfers(status);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    id SERIAL PRIMARY KEY,
+    transfer_id INT NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products(id),
+    quantity INT NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_items_transfer_id ON stock_transfer_items(transfer_id);
+
+-- Shifts are opened at a location and checkouts are tied to it
+ALTER TABLE shifts ADD COLUMN IF NOT EXISTS location_id INT REFERENCES locations(id);
+UPDATE shifts SET location_id = (SELECT id FROM locations WHERE code = 'MAIN') WHERE location_id IS NULL;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS location_id INT REFERENCES locations(id);
+UPDATE transactions SET location_id = (SELECT id FROM locations WHERE code = 'MAIN') WHERE location_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_transactions_location_id ON transactions(location_id);
@@ -0,0 +1,37 @@
+-- Create stocktakes table for physical inventory counts per location
+CREATE TABLE IF NOT EXISTS stocktakes (
+    id SERIAL PRIMARY KEY,
+    location_id INT NOT NULL REFERENCES locations(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'counting' CHECK (status IN ('counting', 'approved', 'cancelled')),
+    note TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ
+);
+
+-- Only one count can run per location at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stocktakes_counting_location ON stocktakes(location_id) WHERE status = 'counting';
+
+-- expected is the stock when the count started, system_quantity the stock at
+-- the moment the product was last counted
+CREATE TABLE IF NOT EXISTS stocktake_items (
+    stocktake_id INT NOT NULL REFERENCES stocktakes(id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products(id),
+    expected INT NOT NULL DEFAULT 0,
+    system_quantity INT,
+    counted INT,
+    counted_at TIMESTAMPTZ,
+    adjustment INT,
+    PRIMARY KEY (stocktake_id, product_id)
+);
+
+-- Every count submitted by a device, counts of the same product add up
+CREATE TABLE IF NOT EXISTS stocktake_counts (
+    id SERIAL PRIMARY KEY,
+    stocktake_id INT NOT NULL REFERENCES stocktakes(id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products(id),
+    quantity INT NOT NULL CHECK (quantity >= 0),
+    device VARCHAR(100) NOT NULL DEFAULT '',
+    counted_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stocktake_counts_stocktake_id ON stocktake_counts(stocktake_id);
@@ -0,0 +1,47 @@
+-- Goods receipts book incoming stock into a location as batches
+CREATE TABLE IF NOT EXISTS goods_receipts (
+    id SERIAL PRIMARY KEY,
+    location_id INT NOT NULL REFERENCES locations(id),
+    supplier VARCHAR(255) NOT NULL DEFAULT '',
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    received_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Stock per batch/lot at a location. Stock not covered by batches (e.g. from
+-- before batch tracking) stays untracked in stock_levels.
+CREATE TABLE IF NOT EXISTS product_batches (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id),
+    location_id INT NOT NULL REFERENCES locations(id),
+    batch_number VARCHAR(100) NOT NULL,
+    expiry_date DATE,
+    quantity INT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    received_quantity INT NOT NULL DEFAULT 0,
+    unit_cost INT NOT NULL DEFAULT 0,
+    goods_receipt_id INT REFERENCES goods_receipts(id),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_batches_product_location ON product_batches(product_id, location_id, expiry_date);
+CREATE INDEX IF NOT EXISTS idx_product_batches_expiry_date ON product_batches(expiry_date) WHERE quantity > 0;
+
+-- Batches a transaction detail was sold from
+CREATE TABLE IF NOT EXISTS transaction_detail_batches (
+    id SERIAL PRIMARY KEY,
+    transaction_detail_id INT NOT NULL REFERENCES transaction_details(id) ON DELETE CASCADE,
+    batch_id INT NOT NULL REFERENCES product_batches(id),
+    quantity INT NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_detail_batches_detail_id ON transaction_detail_batches(transaction_detail_id);
+
+-- Batches shipped on a stock transfer
+CREATE TABLE IF NOT EXISTS stock_transfer_batches (
+    id SERIAL PRIMARY KEY,
+    transfer_id INT NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    batch_id INT NOT NULL REFERENCES product_batches(id),
+    quantity INT NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_batches_transfer_id ON stock_transfer_batches(transfer_id);
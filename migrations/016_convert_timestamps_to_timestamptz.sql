@@ -0,0 +1,15 @@
+-- The first tables stored naive TIMESTAMP values written with CURRENT_TIMESTAMP
+-- in the session timezone. Converting them uses the session timezone as well,
+-- so run this with the same TimeZone setting the server has been writing with.
+ALTER TABLE categories
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN deleted_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE products
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN deleted_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE transactions
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ;
@@ -0,0 +1,30 @@
+-- Daily sales rollups so reports over months or years do not scan every
+-- transaction. Days follow STORE_TIMEZONE; location_id is 0 for transactions
+-- without a location. A refund is booked as a negative entry on the day it
+-- was refunded on, so closed days never change. Fill them for existing history
+-- with `main -rebuild-rollups`.
+CREATE TABLE IF NOT EXISTS daily_sales (
+    sale_date DATE NOT NULL,
+    location_id INT NOT NULL DEFAULT 0,
+    product_id INT NOT NULL REFERENCES products(id),
+    quantity INT NOT NULL DEFAULT 0,
+    gross_sales BIGINT NOT NULL DEFAULT 0,
+    discount_amount NUMERIC(18, 4) NOT NULL DEFAULT 0,
+    tax_amount NUMERIC(18, 4) NOT NULL DEFAULT 0,
+    cost_quantity INT NOT NULL DEFAULT 0,
+    cost_amount BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (sale_date, location_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_daily_sales_product_id ON daily_sales(product_id, sale_date);
+
+-- Transaction counts cannot be summed from the product rows, so each day and
+-- location also keeps its totals
+CREATE TABLE IF NOT EXISTS daily_transactions (
+    sale_date DATE NOT NULL,
+    location_id INT NOT NULL DEFAULT 0,
+    transaction_count INT NOT NULL DEFAULT 0,
+    revenue BIGINT NOT NULL DEFAULT 0,
+    items_sold INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (sale_date, location_id)
+);
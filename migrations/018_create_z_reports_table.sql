@@ -0,0 +1,50 @@
+-- End-of-day Z-reports. Each outlet closes a business day once; the numbers
+-- run per location in the order the days were closed.
+CREATE TABLE IF NOT EXISTS z_reports (
+    id SERIAL PRIMARY KEY,
+    location_id INT NOT NULL REFERENCES locations(id),
+    number INT NOT NULL,
+    business_date DATE NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    transaction_count INT NOT NULL DEFAULT 0,
+    gross_sales BIGINT NOT NULL DEFAULT 0,
+    discount_amount BIGINT NOT NULL DEFAULT 0,
+    net_sales BIGINT NOT NULL DEFAULT 0,
+    tax_amount BIGINT NOT NULL DEFAULT 0,
+    change_given BIGINT NOT NULL DEFAULT 0,
+    void_count INT NOT NULL DEFAULT 0,
+    void_amount BIGINT NOT NULL DEFAULT 0,
+    refund_count INT NOT NULL DEFAULT 0,
+    refund_amount BIGINT NOT NULL DEFAULT 0,
+    first_invoice VARCHAR(100) NOT NULL DEFAULT '',
+    last_invoice VARCHAR(100) NOT NULL DEFAULT '',
+    closed_by VARCHAR(100) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    closed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (location_id, number),
+    UNIQUE (location_id, business_date)
+);
+
+CREATE TABLE IF NOT EXISTS z_report_payments (
+    z_report_id INT NOT NULL REFERENCES z_reports(id),
+    method VARCHAR(50) NOT NULL,
+    amount BIGINT NOT NULL,
+    PRIMARY KEY (z_report_id, method)
+);
+
+-- A closed day is final: Z-reports can only be inserted
+CREATE OR REPLACE FUNCTION reject_z_report_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'Z-report tidak dapat diubah atau dihapus';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS z_reports_immutable ON z_reports;
+CREATE TRIGGER z_reports_immutable BEFORE UPDATE OR DELETE ON z_reports
+    FOR EACH ROW EXECUTE FUNCTION reject_z_report_change();
+
+DROP TRIGGER IF EXISTS z_report_payments_immutable ON z_report_payments;
+CREATE TRIGGER z_report_payments_immutable BEFORE UPDATE OR DELETE ON z_report_payments
+    FOR EACH ROW EXECUTE FUNCTION reject_z_report_change();
@@ -0,0 +1,4 @@
+-- A refund is paid out of the drawer of the shift open when it is made; the
+-- sale itself stays in the shift it was made in
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refund_shift_id INT REFERENCES shifts(id);
+CREATE INDEX IF NOT EXISTS idx_transactions_refund_shift_id ON transactions(refund_shift_id);
@@ -0,0 +1,4 @@
+-- Invoice numbers only have to be unique within a store, so formats without
+-- {STORE} can be used when several stores share the database
+DROP INDEX IF EXISTS idx_transactions_invoice_number;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_store_invoice_number ON transactions(store_code, invoice_number);
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+type Batch struct {
+	ID               int       `json:"id"`
+	ProductID        int       `json:"product_id"`
+	ProductName      string    `json:"product_name,omitempty"`
+	LocationID       int       `json:"location_id"`
+	BatchNumber      string    `json:"batch_number"`
+	ExpiryDate       *string   `json:"expiry_date"`
+	Quantity         int       `json:"quantity"`
+	ReceivedQuantity int       `json:"received_quantity"`
+	UnitCost         int       `json:"unit_cost"`
+	GoodsReceiptID   *int      `json:"goods_receipt_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type BatchAllocation struct {
+	BatchID     int     `json:"batch_id"`
+	BatchNumber string  `json:"batch_number"`
+	ExpiryDate  *string `json:"expiry_date"`
+	Quantity    int     `json:"quantity"`
+}
+
+type GoodsReceipt struct {
+	ID         int       `json:"id"`
+	LocationID int       `json:"location_id"`
+	Supplier   string    `json:"supplier"`
+	Reference  string    `json:"reference"`
+	Note       string    `json:"note"`
+	ReceivedAt time.Time `json:"received_at"`
+	Items      []Batch   `json:"items"`
+}
+
+type ExpiringBatch struct {
+	Batch
+	LocationCode string `json:"location_code"`
+	DaysLeft     int    `json:"days_left"`
+	Expired      bool   `json:"expired"`
+}
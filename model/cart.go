@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+const (
+	CartStatusOpen       = "open"
+	CartStatusParked     = "parked"
+	CartStatusCheckedOut = "checked_out"
+	CartStatusDiscarded  = "discarded"
+)
+
+type Cart struct {
+	ID            int        `json:"id"`
+	Cashier       string     `json:"cashier"`
+	CustomerID    *int       `json:"customer_id,omitempty"`
+	Status        string     `json:"status"`
+	ReserveStock  bool       `json:"reserve_stock"`
+	ReservedUntil *time.Time `json:"reserved_until,omitempty"`
+	Note          string     `json:"note"`
+	TransactionID *int       `json:"transaction_id,omitempty"`
+	TotalAmount   int        `json:"total_amount"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Items         []CartItem `json:"items"`
+}
+
+type CartItem struct {
+	CheckoutItem
+	ProductName string `json:"product_name"`
+	Price       int    `json:"price"`
+	Subtotal    int    `json:"subtotal"`
+}
+
+type ResumeCartRequest struct {
+	Cashier string `json:"cashier"`
+}
+
+type CartCheckoutRequest struct {
+	Cashier      string    `json:"cashier"`
+	RedeemPoints int       `json:"redeem_points"`
+	Payments     []Payment `json:"payments"`
+}
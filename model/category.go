@@ -1,7 +1,10 @@
 package model
 
+import "time"
+
 type Category struct {
-	Id          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-}
\ No newline at end of file
+	Id          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
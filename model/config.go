@@ -3,4 +3,23 @@ package model
 type Config struct {
 	Port   string `mapstructure:"PORT"`
 	DBConn string `mapstructure:"DB_CONN"`
+
+	StoreCode     string  `mapstructure:"STORE_CODE"`
+	InvoiceFormat string  `mapstructure:"INVOICE_FORMAT"`
+	TaxRate       float64 `mapstructure:"TAX_RATE"`
+
+	DefaultLocation string `mapstructure:"DEFAULT_LOCATION"`
+	StoreTimezone   string `mapstructure:"STORE_TIMEZONE"`
+
+	ReportMaxRangeDays int `mapstructure:"REPORT_MAX_RANGE_DAYS"`
+
+	AllowedOrigins string `mapstructure:"ALLOWED_ORIGINS"`
+
+	LoyaltyEarnAmount  int `mapstructure:"LOYALTY_EARN_AMOUNT"`
+	LoyaltyRedeemValue int `mapstructure:"LOYALTY_REDEEM_VALUE"`
+	LoyaltyExpiryDays  int `mapstructure:"LOYALTY_EXPIRY_DAYS"`
+
+	CartReservationMinutes  int `mapstructure:"CART_RESERVATION_MINUTES"`
+	ReservationTTLMinutes   int `mapstructure:"RESERVATION_TTL_MINUTES"`
+	ReservationSweepSeconds int `mapstructure:"RESERVATION_SWEEP_SECONDS"`
 }
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type Customer struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	Address   string    `json:"address"`
+	Points    int       `json:"points_balance"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CustomerTransactionsResponse struct {
+	Customer         *Customer     `json:"customer"`
+	TotalTransaction int           `json:"total_transaksi"`
+	LifetimeSpend    int           `json:"lifetime_spend"`
+	Transactions     []Transaction `json:"transactions"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+const (
+	LocationTypeOutlet    = "outlet"
+	LocationTypeWarehouse = "warehouse"
+)
+
+const (
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+type Location struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type StockLevel struct {
+	LocationID   int    `json:"location_id"`
+	LocationCode string `json:"location_code,omitempty"`
+	ProductID    int    `json:"product_id"`
+	ProductName  string `json:"product_name,omitempty"`
+	Quantity     int    `json:"quantity"`
+}
+
+type SetStockRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+type ProductStock struct {
+	ProductID int          `json:"product_id"`
+	Stock     int          `json:"stock"`
+	InTransit int          `json:"in_transit"`
+	Locations []StockLevel `json:"locations"`
+}
+
+type StockTransfer struct {
+	ID             int            `json:"id"`
+	FromLocationID int            `json:"from_location_id"`
+	ToLocationID   int            `json:"to_location_id"`
+	Status         string         `json:"status"`
+	Note           string         `json:"note"`
+	CreatedAt      time.Time      `json:"created_at"`
+	CompletedAt    *time.Time     `json:"completed_at,omitempty"`
+	Items          []CheckoutItem `json:"items"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	PointEntryEarn   = "earn"
+	PointEntryRedeem = "redeem"
+	PointEntryExpire = "expire"
+	PointEntryRefund = "refund"
+)
+
+type PointEntry struct {
+	ID            int        `json:"id"`
+	CustomerID    int        `json:"customer_id"`
+	TransactionID *int       `json:"transaction_id,omitempty"`
+	Type          string     `json:"type"`
+	Points        int        `json:"points"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type LoyaltyRule struct {
+	EarnAmount  int
+	RedeemValue int
+	ExpiryDays  int
+}
+
+type PointsResponse struct {
+	CustomerID int          `json:"customer_id"`
+	Balance    int          `json:"balance"`
+	Entries    []PointEntry `json:"entries"`
+}
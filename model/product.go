@@ -1,10 +1,15 @@
 package model
 
+import "time"
+
 type Product struct {
-	ID         int       `json:"id"`
-	Name       string    `json:"name"`
-	Price      int       `json:"price"`
-	Stock      int       `json:"stock"`
-	CategoryID int       `json:"category_id"`
-	Category   *Category `json:"category"`
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Price      int        `json:"price"`
+	Stock      int        `json:"stock"`
+	Reserved   int        `json:"reserved"`
+	Available  int        `json:"available"`
+	CategoryID int        `json:"category_id"`
+	Category   *Category  `json:"category"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 }
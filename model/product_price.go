@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type ProductPrice struct {
+	ID            int       `json:"id"`
+	ProductID     int       `json:"product_id"`
+	Price         int       `json:"price"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type SchedulePriceRequest struct {
+	Price         int        `json:"price"`
+	EffectiveFrom *time.Time `json:"effective_from"`
+}
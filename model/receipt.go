@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	ReceiptFormatText   = "text"
+	ReceiptFormatHTML   = "html"
+	ReceiptFormatPDF    = "pdf"
+	ReceiptFormatESCPOS = "escpos"
+)
+
+type ReceiptSettings struct {
+	StoreName string    `json:"store_name"`
+	Address   string    `json:"address"`
+	Phone     string    `json:"phone"`
+	Footer    string    `json:"footer"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ReceiptTemplate struct {
+	Format    string     `json:"format"`
+	Content   string     `json:"content"`
+	IsDefault bool       `json:"is_default"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Receipt is the data passed to receipt templates.
+type Receipt struct {
+	Store       ReceiptSettings
+	Transaction Transaction
+	Subtotal    int
+	Width       int
+	Paper       int
+}
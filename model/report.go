@@ -0,0 +1,172 @@
+package model
+
+import "time"
+
+const (
+	ReportIntervalHour  = "hour"
+	ReportIntervalDay   = "day"
+	ReportIntervalWeek  = "week"
+	ReportIntervalMonth = "month"
+)
+
+// ReportFilter selects the transactions of a report: those created in the
+// half-open range [From, To), optionally at one location. Buckets and day
+// boundaries are computed in Timezone.
+type ReportFilter struct {
+	From       time.Time
+	To         time.Time
+	Timezone   *time.Location
+	LocationID int
+}
+
+type TimeseriesPoint struct {
+	Bucket           time.Time `json:"bucket"`
+	Revenue          int       `json:"revenue"`
+	TransactionCount int       `json:"transaction_count"`
+	ItemsSold        int       `json:"items_sold"`
+	AverageBasket    int       `json:"average_basket"`
+}
+
+type TimeseriesResponse struct {
+	Interval string            `json:"interval"`
+	Timezone string            `json:"timezone"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Points   []TimeseriesPoint `json:"points"`
+}
+
+const (
+	ReportRankByQuantity = "quantity"
+	ReportRankByRevenue  = "revenue"
+)
+
+type TopItem struct {
+	Rank     int     `json:"rank"`
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Revenue  int     `json:"revenue"`
+	Share    float64 `json:"share"`
+}
+
+type TopResponse struct {
+	By            string    `json:"by"`
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	TotalQuantity int       `json:"total_quantity"`
+	TotalRevenue  int       `json:"total_revenue"`
+	Items         []TopItem `json:"items"`
+}
+
+const (
+	BreakdownGroupCategory = "category"
+	BreakdownGroupProduct  = "product"
+)
+
+// BreakdownRow is the sales of one product or category. Discount and tax of
+// a transaction are spread over its lines by subtotal. Cost and Profit are
+// only set when the whole quantity was sold from batches with a known cost.
+type BreakdownRow struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	CategoryID   int    `json:"category_id,omitempty"`
+	CategoryName string `json:"category_name,omitempty"`
+	Quantity     int    `json:"quantity"`
+	GrossSales   int    `json:"gross_sales"`
+	Discount     int    `json:"discount"`
+	NetSales     int    `json:"net_sales"`
+	Tax          int    `json:"tax"`
+	Cost         *int   `json:"cost"`
+	Profit       *int   `json:"profit"`
+	CostQuantity int    `json:"-"`
+	CostAmount   int    `json:"-"`
+}
+
+type SalesBreakdown struct {
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Total      BreakdownRow   `json:"total"`
+	Categories []BreakdownRow `json:"categories,omitempty"`
+	Products   []BreakdownRow `json:"products,omitempty"`
+}
+
+// BasketProduct is one side of a product pair with the number of
+// transactions it appears in.
+type BasketProduct struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Transactions int    `json:"transactions"`
+}
+
+// ProductPair is two products bought in the same transaction. Support is the
+// share of all transactions holding both, confidence the share of the
+// transactions of one product that also hold the other, and lift how much
+// more often they are bought together than if they were independent.
+type ProductPair struct {
+	ProductA       BasketProduct `json:"product_a"`
+	ProductB       BasketProduct `json:"product_b"`
+	Transactions   int           `json:"transactions"`
+	Support        float64       `json:"support"`
+	ConfidenceAToB float64       `json:"confidence_a_to_b"`
+	ConfidenceBToA float64       `json:"confidence_b_to_a"`
+	Lift           float64       `json:"lift"`
+}
+
+type BasketAnalysis struct {
+	From              time.Time     `json:"from"`
+	To                time.Time     `json:"to"`
+	TotalTransactions int           `json:"total_transactions"`
+	MinTransactions   int           `json:"min_transactions"`
+	Pairs             []ProductPair `json:"pairs"`
+}
+
+// RelatedProduct is a product bought together with another one. Confidence
+// is the share of the other product's transactions that also hold this one.
+type RelatedProduct struct {
+	ID           int     `json:"id"`
+	Name         string  `json:"name"`
+	Price        int     `json:"price"`
+	Transactions int     `json:"transactions"`
+	Confidence   float64 `json:"confidence"`
+	Lift         float64 `json:"lift"`
+}
+
+type RelatedProducts struct {
+	ProductID    int              `json:"product_id"`
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	Transactions int              `json:"transactions"`
+	Related      []RelatedProduct `json:"related"`
+}
+
+// InventoryRow is the stock on hand of a product, of a category or in total.
+// CostValue is only set when the cost of every unit is known.
+type InventoryRow struct {
+	ID                int      `json:"id"`
+	Name              string   `json:"name"`
+	CategoryID        int      `json:"category_id,omitempty"`
+	CategoryName      string   `json:"category_name,omitempty"`
+	Price             int      `json:"price,omitempty"`
+	Quantity          int      `json:"quantity"`
+	CostValue         *int     `json:"cost_value"`
+	RetailValue       int      `json:"retail_value"`
+	SoldQuantity      int      `json:"sold_quantity"`
+	AverageDailySales float64  `json:"average_daily_sales"`
+	DaysOfCover       *float64 `json:"days_of_cover"`
+	LastSoldDate      string   `json:"last_sold_date,omitempty"`
+	CostQuantity      int      `json:"-"`
+	CostAmount        int      `json:"-"`
+}
+
+// InventoryReport values the stock on hand on Date. Sales are averaged over
+// the last SalesDays days; dead stock has not sold in DeadStockDays days.
+type InventoryReport struct {
+	Date          string         `json:"date"`
+	LocationID    int            `json:"location_id,omitempty"`
+	SalesDays     int            `json:"sales_days"`
+	DeadStockDays int            `json:"dead_stock_days"`
+	Total         InventoryRow   `json:"total"`
+	Categories    []InventoryRow `json:"categories,omitempty"`
+	Products      []InventoryRow `json:"products,omitempty"`
+	DeadStock     []InventoryRow `json:"dead_stock"`
+}
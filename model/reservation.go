@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type Reservation struct {
+	Reference string            `json:"reference"`
+	Items     []ReservationItem `json:"items"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type ReservationItem struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+}
+
+type CreateReservationRequest struct {
+	Reference  string         `json:"reference"`
+	Items      []CheckoutItem `json:"items"`
+	TTLMinutes int            `json:"ttl_minutes"`
+}
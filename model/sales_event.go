@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+const (
+	SalesEventCheckout = "checkout"
+	SalesEventRefund   = "refund"
+	SalesEventTotals   = "totals"
+)
+
+// SalesTotals are the running totals of the current business day, over all
+// locations when LocationID is zero.
+type SalesTotals struct {
+	Date             string `json:"date"`
+	LocationID       int    `json:"location_id,omitempty"`
+	Revenue          int    `json:"revenue"`
+	TransactionCount int    `json:"transaction_count"`
+	AverageBasket    int    `json:"average_basket"`
+}
+
+// SalesEvent is pushed on the live sales feed for every checkout and refund.
+// A totals event without a transaction is sent when a client connects.
+type SalesEvent struct {
+	ID          int64        `json:"id,omitempty"`
+	Type        string       `json:"type"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Today       SalesTotals  `json:"today"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
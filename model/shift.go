@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+const (
+	CashEventIn  = "in"
+	CashEventOut = "out"
+)
+
+type Shift struct {
+	ID           int        `json:"id"`
+	Cashier      string     `json:"cashier"`
+	LocationID   int        `json:"location_id"`
+	OpeningFloat int        `json:"opening_float"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+	ExpectedCash *int       `json:"expected_cash,omitempty"`
+	CountedCash  *int       `json:"counted_cash,omitempty"`
+	Note         string     `json:"note"`
+}
+
+type CashEvent struct {
+	ID        int       `json:"id"`
+	ShiftID   int       `json:"shift_id"`
+	Type      string    `json:"type"`
+	Amount    int       `json:"amount"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CloseShiftRequest struct {
+	CountedCash int    `json:"counted_cash"`
+	Note        string `json:"note"`
+}
+
+type PaymentTotal struct {
+	Method string `json:"method"`
+	Amount int    `json:"amount"`
+}
+
+type ShiftSales struct {
+	TotalTransaction int            `json:"total_transaksi"`
+	TotalSales       int            `json:"total_sales"`
+	ChangeGiven      int            `json:"change_given"`
+	Payments         []PaymentTotal `json:"payments"`
+}
+
+type ShiftReport struct {
+	Shift        Shift       `json:"shift"`
+	Sales        ShiftSales  `json:"sales"`
+	Refunds      ShiftSales  `json:"refunds"`
+	CashSales    int         `json:"cash_sales"`
+	CashRefunds  int         `json:"cash_refunds"`
+	CashIn       int         `json:"cash_in"`
+	CashOut      int         `json:"cash_out"`
+	ExpectedCash int         `json:"expected_cash"`
+	CountedCash  *int        `json:"counted_cash,omitempty"`
+	OverShort    *int        `json:"over_short,omitempty"`
+	CashEvents   []CashEvent `json:"cash_events"`
+}
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+const (
+	StocktakeStatusCounting  = "counting"
+	StocktakeStatusApproved  = "approved"
+	StocktakeStatusCancelled = "cancelled"
+)
+
+type Stocktake struct {
+	ID          int        `json:"id"`
+	LocationID  int        `json:"location_id"`
+	Status      string     `json:"status"`
+	Note        string     `json:"note"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type StocktakeItem struct {
+	ProductID      int        `json:"product_id"`
+	ProductName    string     `json:"product_name"`
+	Price          int        `json:"price"`
+	Expected       int        `json:"expected"`
+	SystemQuantity *int       `json:"system_quantity"`
+	Counted        *int       `json:"counted"`
+	CountedAt      *time.Time `json:"counted_at,omitempty"`
+	Adjustment     *int       `json:"adjustment,omitempty"`
+}
+
+type StocktakeCountRequest struct {
+	Device string         `json:"device"`
+	Counts []CheckoutItem `json:"counts"`
+}
+
+type ApproveStocktakeRequest struct {
+	ZeroUncounted bool `json:"zero_uncounted"`
+}
+
+type StocktakeVarianceRow struct {
+	ProductID      int    `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	Expected       int    `json:"expected"`
+	SystemQuantity int    `json:"system_quantity"`
+	Counted        int    `json:"counted"`
+	Variance       int    `json:"variance"`
+	VarianceValue  int    `json:"variance_value"`
+}
+
+type StocktakeVarianceReport struct {
+	Stocktake      Stocktake              `json:"stocktake"`
+	TotalItems     int                    `json:"total_items"`
+	CountedItems   int                    `json:"counted_items"`
+	UncountedItems int                    `json:"uncounted_items"`
+	TotalVariance  int                    `json:"total_variance"`
+	VarianceValue  int                    `json:"variance_value"`
+	Rows           []StocktakeVarianceRow `json:"rows"`
+}
@@ -1,19 +1,46 @@
 package model
 
+import "time"
+
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	CreatedAt   string              `json:"created_at"`
-	Details     []TransactionDetail `json:"details,omitempty"`
+	ID             int                 `json:"id"`
+	InvoiceNumber  string              `json:"invoice_number"`
+	StoreCode      string              `json:"store_code"`
+	TotalAmount    int                 `json:"total_amount"`
+	DiscountAmount int                 `json:"discount_amount"`
+	TaxAmount      int                 `json:"tax_amount"`
+	PaidAmount     int                 `json:"paid_amount"`
+	ChangeAmount   int                 `json:"change_amount"`
+	CustomerID     *int                `json:"customer_id,omitempty"`
+	ShiftID        *int                `json:"shift_id,omitempty"`
+	LocationID     *int                `json:"location_id,omitempty"`
+	PointsRedeemed int                 `json:"points_redeemed,omitempty"`
+	PointsEarned   int                 `json:"points_earned,omitempty"`
+	CreatedAt      string              `json:"created_at"`
+	RefundedAt     *time.Time          `json:"refunded_at,omitempty"`
+	RefundShiftID  *int                `json:"refund_shift_id,omitempty"`
+	Details        []TransactionDetail `json:"details,omitempty"`
+	Payments       []Payment           `json:"payments,omitempty"`
 }
 
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name,omitempty"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID            int               `json:"id"`
+	TransactionID int               `json:"transaction_id"`
+	ProductID     int               `json:"product_id"`
+	ProductName   string            `json:"product_name,omitempty"`
+	Price         int               `json:"price"`
+	Quantity      int               `json:"quantity"`
+	Subtotal      int               `json:"subtotal"`
+	Batches       []BatchAllocation `json:"batches,omitempty"`
+}
+
+const PaymentMethodCash = "cash"
+
+type Payment struct {
+	ID            int    `json:"id,omitempty"`
+	TransactionID int    `json:"transaction_id,omitempty"`
+	Method        string `json:"method"`
+	Amount        int    `json:"amount"`
 }
 
 type CheckoutItem struct {
@@ -22,16 +49,68 @@ type CheckoutItem struct {
 }
 
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	Cashier      string         `json:"cashier"`
+	Items        []CheckoutItem `json:"items"`
+	CustomerID   *int           `json:"customer_id"`
+	RedeemPoints int            `json:"redeem_points"`
+	Payments     []Payment      `json:"payments"`
+	Reservation  string         `json:"reservation"`
+	CartID       int            `json:"-"`
 }
 
 type SummaryResponse struct {
-	TotalRevenue     int             `json:"total_revenue"`
-	TotalTransaction int             `json:"total_transaksi"`
-	ProductTerlaris  ProductTerlaris `json:"produk_terlaris"`
+	TotalRevenue     int                `json:"total_revenue"`
+	TotalTransaction int                `json:"total_transaksi"`
+	AverageBasket    int                `json:"average_basket"`
+	ProductTerlaris  ProductTerlaris    `json:"produk_terlaris"`
+	Comparison       *SummaryComparison `json:"comparison,omitempty"`
+}
+
+const (
+	CompareNone           = ""
+	ComparePreviousPeriod = "previous_period"
+	CompareLastYear       = "last_year"
+)
+
+// SummaryComparison compares a summary against an earlier period.
+type SummaryComparison struct {
+	Compare          string      `json:"compare"`
+	From             time.Time   `json:"from"`
+	To               time.Time   `json:"to"`
+	Revenue          MetricDelta `json:"revenue"`
+	TransactionCount MetricDelta `json:"transaction_count"`
+	AverageBasket    MetricDelta `json:"average_basket"`
+}
+
+// MetricDelta is the change of a metric against the earlier period.
+// ChangePercent is null when the earlier value is zero.
+type MetricDelta struct {
+	Current       int      `json:"current"`
+	Previous      int      `json:"previous"`
+	Change        int      `json:"change"`
+	ChangePercent *float64 `json:"change_percent"`
 }
 
 type ProductTerlaris struct {
 	Name       string `json:"nama"`
 	QtyTerjual int    `json:"qty_terjual"`
 }
+
+type CheckoutConfig struct {
+	StoreCode       string
+	InvoiceFormat   string
+	TaxRate         float64
+	DefaultLocation string
+	Timezone        *time.Location
+}
+
+type ReportConfig struct {
+	Timezone     *time.Location
+	MaxRangeDays int
+}
+
+// RefundRequest names the cashier who pays the refund out of their open
+// shift. It defaults to the cashier who made the sale.
+type RefundRequest struct {
+	Cashier string `json:"cashier"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// ZReport is the end-of-day closing of a location. Sales are the
+// transactions created during the day that were not refunded before it
+// ended; voids are the ones refunded the same day. Refunds are transactions
+// from earlier days refunded during the day.
+type ZReport struct {
+	ID               int            `json:"id"`
+	Number           int            `json:"number"`
+	LocationID       int            `json:"location_id"`
+	BusinessDate     string         `json:"business_date"`
+	Timezone         string         `json:"timezone"`
+	PeriodStart      time.Time      `json:"period_start"`
+	PeriodEnd        time.Time      `json:"period_end"`
+	TransactionCount int            `json:"transaction_count"`
+	GrossSales       int            `json:"gross_sales"`
+	Discount         int            `json:"discount"`
+	NetSales         int            `json:"net_sales"`
+	Tax              int            `json:"tax"`
+	ChangeGiven      int            `json:"change_given"`
+	Payments         []PaymentTotal `json:"payments"`
+	VoidCount        int            `json:"void_count"`
+	VoidAmount       int            `json:"void_amount"`
+	RefundCount      int            `json:"refund_count"`
+	RefundAmount     int            `json:"refund_amount"`
+	FirstInvoice     string         `json:"first_invoice"`
+	LastInvoice      string         `json:"last_invoice"`
+	ClosedBy         string         `json:"closed_by"`
+	Note             string         `json:"note"`
+	ClosedAt         time.Time      `json:"closed_at"`
+}
+
+// CloseDayRequest closes Date, today when empty, at LocationID, the default
+// location when zero.
+type CloseDayRequest struct {
+	LocationID int    `json:"location_id"`
+	Date       string `json:"date"`
+	ClosedBy   string `json:"closed_by"`
+	Note       string `json:"note"`
+}
+
+// ZReportPrint is the data of a printed Z-report.
+type ZReportPrint struct {
+	Store    ReceiptSettings
+	Location Location
+	Report   ZReport
+	Width    int
+}
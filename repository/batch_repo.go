@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+	"strconv"
+)
+
+var (
+	ErrGoodsReceiptNotFound = errors.New("penerimaan barang tidak ditemukan")
+	ErrBatchStockNotEnough  = errors.New("stok produk yang belum kedaluwarsa tidak cukup")
+)
+
+const batchColumns = "b.id, b.product_id, p.name, b.location_id, b.batch_number, TO_CHAR(b.expiry_date, 'YYYY-MM-DD'), b.quantity, b.received_quantity, b.unit_cost, b.goods_receipt_id, b.created_at"
+
+type BatchRepositoryInterface interface {
+	BeginTrans() (*sql.Tx, error)
+	CommitTrans(tx *sql.Tx) error
+	RollbackTrans(tx *sql.Tx) error
+	CreateReceipt(tx *sql.Tx, receipt *model.GoodsReceipt) error
+	GetReceipts(locationID int) ([]model.GoodsReceipt, error)
+	GetReceiptByID(id int) (*model.GoodsReceipt, error)
+	GetByProduct(productID int, locationID int, includeEmpty bool) ([]model.Batch, error)
+	GetExpiring(today string, days int, locationID int) ([]model.ExpiringBatch, error)
+	LockSellable(tx *sql.Tx, productID int, locationID int, today string) ([]model.Batch, error)
+	GetTrackedQuantity(tx *sql.Tx, productID int, locationID int) (int, error)
+	AdjustQuantity(tx *sql.Tx, batchID int, delta int) error
+	CopyTo(tx *sql.Tx, batchID int, locationID int, quantity int) error
+	SaveTransferAllocations(tx *sql.Tx, transferID int, allocations []model.BatchAllocation) error
+	GetTransferAllocations(tx *sql.Tx, transferID int) ([]model.BatchAllocation, error)
+}
+
+type batchRepository struct {
+	db *sql.DB
+}
+
+func NewBatchRepository(db *sql.DB) BatchRepositoryInterface {
+	return &batchRepository{db: db}
+}
+
+func (repo *batchRepository) BeginTrans() (*sql.Tx, error) {
+	return repo.db.Begin()
+}
+
+func (repo *batchRepository) CommitTrans(tx *sql.Tx) error {
+	return tx.Commit()
+}
+
+func (repo *batchRepository) RollbackTrans(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+func (repo *batchRepository) CreateReceipt(tx *sql.Tx, receipt *model.GoodsReceipt) error {
+	query := "INSERT INTO goods_receipts (location_id, supplier, reference, note) VALUES ($1, $2, $3, $4) RETURNING id, received_at"
+	err := tx.QueryRow(query, receipt.LocationID, receipt.Supplier, receipt.Reference, receipt.Note).Scan(&receipt.ID, &receipt.ReceivedAt)
+	if err != nil {
+		return err
+	}
+
+	batchQuery := `INSERT INTO product_batches (product_id, location_id, batch_number, expiry_date, quantity, received_quantity, unit_cost, goods_receipt_id)
+		VALUES ($1, $2, $3, $4, $5, $5, $6, $7) RETURNING id, created_at`
+	for i := range receipt.Items {
+		item := &receipt.Items[i]
+		item.LocationID = receipt.LocationID
+		item.GoodsReceiptID = &receipt.ID
+		item.ReceivedQuantity = item.Quantity
+		err = tx.QueryRow(batchQuery, item.ProductID, item.LocationID, item.BatchNumber, item.ExpiryDate, item.Quantity, item.UnitCost, receipt.ID).Scan(&item.ID, &item.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *batchRepository) GetReceipts(locationID int) ([]model.GoodsReceipt, error) {
+	query := "SELECT id, location_id, supplier, reference, note, received_at FROM goods_receipts"
+	args := []interface{}{}
+	if locationID != 0 {
+		args = append(args, locationID)
+		query += " WHERE location_id = $1"
+	}
+	query += " ORDER BY received_at DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := make([]model.GoodsReceipt, 0)
+	for rows.Next() {
+		var r model.GoodsReceipt
+		err := rows.Scan(&r.ID, &r.LocationID, &r.Supplier, &r.Reference, &r.Note, &r.ReceivedAt)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, r)
+	}
+	return receipts, rows.Err()
+}
+
+func (repo *batchRepository) GetReceiptByID(id int) (*model.GoodsReceipt, error) {
+	query := "SELECT id, location_id, supplier, reference, note, received_at FROM goods_receipts WHERE id = $1"
+	var r model.GoodsReceipt
+	err := repo.db.QueryRow(query, id).Scan(&r.ID, &r.LocationID, &r.Supplier, &r.Reference, &r.Note, &r.ReceivedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrGoodsReceiptNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query("SELECT "+batchColumns+" FROM product_batches b JOIN products p ON p.id = b.product_id WHERE b.goods_receipt_id = $1 ORDER BY b.id", id)
+	if err != nil {
+		return nil, err
+	}
+	r.Items, err = scanBatches(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (repo *batchRepository) GetByProduct(productID int, locationID int, includeEmpty bool) ([]model.Batch, error) {
+	query := "SELECT " + batchColumns + " FROM product_batches b JOIN products p ON p.id = b.product_id WHERE b.product_id = $1"
+	args := []interface{}{productID}
+	if locationID != 0 {
+		args = append(args, locationID)
+		query += " AND b.location_id = $" + strconv.Itoa(len(args))
+	}
+	if !includeEmpty {
+		query += " AND b.quantity > 0"
+	}
+	query += " ORDER BY b.expiry_date NULLS LAST, b.id"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanBatches(rows)
+}
+
+// GetExpiring lists batches with stock left that expire within the given
+// number of days after today (YYYY-MM-DD), including ones that have already
+// expired.
+func (repo *batchRepository) GetExpiring(today string, days int, locationID int) ([]model.ExpiringBatch, error) {
+	query := `SELECT ` + batchColumns + `, l.code, b.expiry_date - $1::date
+		FROM product_batches b
+		JOIN products p ON p.id = b.product_id
+		JOIN locations l ON l.id = b.location_id
+		WHERE b.quantity > 0 AND b.expiry_date IS NOT NULL AND b.expiry_date <= $1::date + $2::int`
+	args := []interface{}{today, days}
+	if locationID != 0 {
+		args = append(args, locationID)
+		query += " AND b.location_id = $3"
+	}
+	query += " ORDER BY b.expiry_date, p.name, b.id"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := make([]model.ExpiringBatch, 0)
+	for rows.Next() {
+		var b model.ExpiringBatch
+		err := rows.Scan(&b.ID, &b.ProductID, &b.ProductName, &b.LocationID, &b.BatchNumber, &b.ExpiryDate, &b.Quantity, &b.ReceivedQuantity, &b.UnitCost, &b.GoodsReceiptID, &b.CreatedAt, &b.LocationCode, &b.DaysLeft)
+		if err != nil {
+			return nil, err
+		}
+		b.Expired = b.DaysLeft < 0
+		batches = append(batches, b)
+	}
+	return batches, rows.Err()
+}
+
+// LockSellable locks the batches of a product at a location that still have
+// stock and have not expired before today (YYYY-MM-DD), earliest expiry first
+// (FEFO). Batches without an expiry date come last.
+func (repo *batchRepository) LockSellable(tx *sql.Tx, productID int, locationID int, today string) ([]model.Batch, error) {
+	query := `SELECT ` + batchColumns + ` FROM product_batches b JOIN products p ON p.id = b.product_id
+		WHERE b.product_id = $1 AND b.location_id = $2 AND b.quantity > 0
+		AND (b.expiry_date IS NULL OR b.expiry_date >= $3::date)
+		ORDER BY b.expiry_date NULLS LAST, b.id FOR UPDATE OF b`
+	rows, err := tx.Query(query, productID, locationID, today)
+	if err != nil {
+		return nil, err
+	}
+	return scanBatches(rows)
+}
+
+// GetTrackedQuantity sums the stock held in batches, expired ones included.
+func (repo *batchRepository) GetTrackedQuantity(tx *sql.Tx, productID int, locationID int) (int, error) {
+	var quantity int
+	err := tx.QueryRow("SELECT COALESCE(SUM(quantity), 0) FROM product_batches WHERE product_id = $1 AND location_id = $2", productID, locationID).Scan(&quantity)
+	return quantity, err
+}
+
+func (repo *batchRepository) AdjustQuantity(tx *sql.Tx, batchID int, delta int) error {
+	_, err := tx.Exec("UPDATE product_batches SET quantity = quantity + $1 WHERE id = $2", delta, batchID)
+	return err
+}
+
+// CopyTo books quantity of a batch into another location, adding to the
+// batch with the same number and expiry there or creating it.
+func (repo *batchRepository) CopyTo(tx *sql.Tx, batchID int, locationID int, quantity int) error {
+	var id int
+	query := `SELECT d.id FROM product_batches d JOIN product_batches s ON s.id = $1
+		WHERE d.product_id = s.product_id AND d.location_id = $2 AND d.batch_number = s.batch_number
+		AND d.expiry_date IS NOT DISTINCT FROM s.expiry_date
+		ORDER BY d.id LIMIT 1 FOR UPDATE OF d`
+	err := tx.QueryRow(query, batchID, locationID).Scan(&id)
+	if err == sql.ErrNoRows {
+		insertQuery := `INSERT INTO product_batches (product_id, location_id, batch_number, expiry_date, quantity, received_quantity, unit_cost)
+			SELECT product_id, $2, batch_number, expiry_date, $3, $3, unit_cost FROM product_batches WHERE id = $1`
+		_, err = tx.Exec(insertQuery, batchID, locationID, quantity)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE product_batches SET quantity = quantity + $1, received_quantity = received_quantity + $1 WHERE id = $2", quantity, id)
+	return err
+}
+
+func (repo *batchRepository) SaveTransferAllocations(tx *sql.Tx, transferID int, allocations []model.BatchAllocation) error {
+	query := "INSERT INTO stock_transfer_batches (transfer_id, batch_id, quantity) VALUES ($1, $2, $3)"
+	for _, allocation := range allocations {
+		_, err := tx.Exec(query, transferID, allocation.BatchID, allocation.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *batchRepository) GetTransferAllocations(tx *sql.Tx, transferID int) ([]model.BatchAllocation, error) {
+	query := `SELECT tb.batch_id, b.batch_number, TO_CHAR(b.expiry_date, 'YYYY-MM-DD'), tb.quantity
+		FROM stock_transfer_batches tb JOIN product_batches b ON b.id = tb.batch_id
+		WHERE tb.transfer_id = $1 ORDER BY tb.id`
+	rows, err := tx.Query(query, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	allocations := make([]model.BatchAllocation, 0)
+	for rows.Next() {
+		var a model.BatchAllocation
+		err := rows.Scan(&a.BatchID, &a.BatchNumber, &a.ExpiryDate, &a.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, a)
+	}
+	return allocations, rows.Err()
+}
+
+func scanBatches(rows *sql.Rows) ([]model.Batch, error) {
+	defer rows.Close()
+
+	batches := make([]model.Batch, 0)
+	for rows.Next() {
+		var b model.Batch
+		err := rows.Scan(&b.ID, &b.ProductID, &b.ProductName, &b.LocationID, &b.BatchNumber, &b.ExpiryDate, &b.Quantity, &b.ReceivedQuantity, &b.UnitCost, &b.GoodsReceiptID, &b.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	return batches, rows.Err()
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+	"strconv"
+)
+
+var (
+	ErrCartNotFound     = errors.New("keranjang tidak ditemukan")
+	ErrCartItemNotFound = errors.New("produk tidak ada di keranjang")
+)
+
+const cartColumns = "id, cashier, customer_id, status, reserve_stock, (SELECT MIN(sr.expires_at) FROM stock_reservations sr WHERE sr.cart_id = carts.id AND sr.expires_at > CURRENT_TIMESTAMP), note, transaction_id, created_at, updated_at"
+
+type CartRepositoryInterface interface {
+	BeginTrans() (*sql.Tx, error)
+	CommitTrans(tx *sql.Tx) error
+	RollbackTrans(tx *sql.Tx) error
+	Create(cart *model.Cart) error
+	GetAll(cashier string, status string) ([]model.Cart, error)
+	GetByID(id int) (*model.Cart, error)
+	Lock(tx *sql.Tx, id int) (*model.Cart, error)
+	Update(tx *sql.Tx, cart *model.Cart) error
+	GetItems(cartID int) ([]model.CartItem, error)
+	SetItem(tx *sql.Tx, cartID int, productID int, quantity int) error
+	RemoveItem(tx *sql.Tx, cartID int, productID int) error
+}
+
+type cartRepository struct {
+	db *sql.DB
+}
+
+func NewCartRepository(db *sql.DB) CartRepositoryInterface {
+	return &cartRepository{db: db}
+}
+
+func (repo *cartRepository) BeginTrans() (*sql.Tx, error) {
+	return repo.db.Begin()
+}
+
+func (repo *cartRepository) CommitTrans(tx *sql.Tx) error {
+	return tx.Commit()
+}
+
+func (repo *cartRepository) RollbackTrans(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+func (repo *cartRepository) Create(cart *model.Cart) error {
+	query := "INSERT INTO carts (cashier, customer_id, status, reserve_stock, note) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at"
+	return repo.db.QueryRow(query, cart.Cashier, cart.CustomerID, cart.Status, cart.ReserveStock, cart.Note).Scan(&cart.ID, &cart.CreatedAt, &cart.UpdatedAt)
+}
+
+func (repo *cartRepository) GetAll(cashier string, status string) ([]model.Cart, error) {
+	query := "SELECT " + cartColumns + " FROM carts WHERE 1 = 1"
+	args := []interface{}{}
+	if cashier != "" {
+		args = append(args, cashier)
+		query += " AND cashier = $1"
+	}
+	if status != "" {
+		args = append(args, status)
+		query += " AND status = $" + strconv.Itoa(len(args))
+	}
+	query += " ORDER BY updated_at DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	carts := make([]model.Cart, 0)
+	for rows.Next() {
+		var c model.Cart
+		err := rows.Scan(&c.ID, &c.Cashier, &c.CustomerID, &c.Status, &c.ReserveStock, &c.ReservedUntil, &c.Note, &c.TransactionID, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		carts = append(carts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range carts {
+		carts[i].Items, err = repo.GetItems(carts[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		carts[i].TotalAmount = cartTotal(carts[i].Items)
+	}
+	return carts, nil
+}
+
+func (repo *cartRepository) GetByID(id int) (*model.Cart, error) {
+	query := "SELECT " + cartColumns + " FROM carts WHERE id = $1"
+	cart, err := scanCart(repo.db.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	cart.Items, err = repo.GetItems(id)
+	if err != nil {
+		return nil, err
+	}
+	cart.TotalAmount = cartTotal(cart.Items)
+	return cart, nil
+}
+
+// Lock locks the cart row so that line changes, parking and checkout of the
+// same cart are serialized. Items are not loaded.
+func (repo *cartRepository) Lock(tx *sql.Tx, id int) (*model.Cart, error) {
+	query := "SELECT " + cartColumns + " FROM carts WHERE id = $1 FOR UPDATE"
+	return scanCart(tx.QueryRow(query, id))
+}
+
+func (repo *cartRepository) Update(tx *sql.Tx, cart *model.Cart) error {
+	query := "UPDATE carts SET cashier = $1, status = $2, transaction_id = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $4 RETURNING updated_at"
+	err := tx.QueryRow(query, cart.Cashier, cart.Status, cart.TransactionID, cart.ID).Scan(&cart.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrCartNotFound
+	}
+	return err
+}
+
+// GetItems returns the cart lines priced at the currently effective price.
+func (repo *cartRepository) GetItems(cartID int) ([]model.CartItem, error) {
+	query := "SELECT ci.product_id, ci.quantity, products.name, " + effectivePrice + " FROM cart_items ci JOIN products ON products.id = ci.product_id WHERE ci.cart_id = $1 ORDER BY ci.id"
+	rows, err := repo.db.Query(query, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]model.CartItem, 0)
+	for rows.Next() {
+		var item model.CartItem
+		err := rows.Scan(&item.ProductID, &item.Quantity, &item.ProductName, &item.Price)
+		if err != nil {
+			return nil, err
+		}
+		item.Subtotal = item.Price * item.Quantity
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (repo *cartRepository) SetItem(tx *sql.Tx, cartID int, productID int, quantity int) error {
+	query := `INSERT INTO cart_items (cart_id, product_id, quantity) VALUES ($1, $2, $3)
+		ON CONFLICT (cart_id, product_id) DO UPDATE SET quantity = EXCLUDED.quantity`
+	_, err := tx.Exec(query, cartID, productID, quantity)
+	return err
+}
+
+func (repo *cartRepository) RemoveItem(tx *sql.Tx, cartID int, productID int) error {
+	result, err := tx.Exec("DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2", cartID, productID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCartItemNotFound
+	}
+	return nil
+}
+
+func scanCart(row *sql.Row) (*model.Cart, error) {
+	var c model.Cart
+	err := row.Scan(&c.ID, &c.Cashier, &c.CustomerID, &c.Status, &c.ReserveStock, &c.ReservedUntil, &c.Note, &c.TransactionID, &c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrCartNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func cartTotal(items []model.CartItem) int {
+	total := 0
+	for _, item := range items {
+		total += item.Subtotal
+	}
+	return total
+}
@@ -2,15 +2,20 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"product-api/model"
 )
 
+var ErrCategoryNotFound = errors.New("kategori tidak ditemukan")
+
 type CategoryRepositoryInterface interface {
-	GetAll() ([]model.Category, error)
+	GetAll(includeDeleted bool) ([]model.Category, error)
 	Create(category *model.Category) error
 	GetByID(id int) (*model.Category, error)
 	Update(category *model.Category) error
 	Delete(id int) error
+	Restore(id int) error
+	HasActiveProducts(id int) (bool, error)
 }
 
 type categoryRepository struct {
@@ -21,8 +26,11 @@ func NewCategoryRepository(db *sql.DB) CategoryRepositoryInterface {
 	return &categoryRepository{db: db}
 }
 
-func (repo *categoryRepository) GetAll() ([]model.Category, error) {
-	query := "SELECT id, name, description FROM categories"
+func (repo *categoryRepository) GetAll(includeDeleted bool) ([]model.Category, error) {
+	query := "SELECT id, name, description, deleted_at FROM categories"
+	if !includeDeleted {
+		query += " WHERE deleted_at IS NULL"
+	}
 	rows, err := repo.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -32,7 +40,7 @@ func (repo *categoryRepository) GetAll() ([]model.Category, error) {
 	categories := make([]model.Category, 0)
 	for rows.Next() {
 		var c model.Category
-		err := rows.Scan(&c.Id, &c.Name, &c.Description)
+		err := rows.Scan(&c.Id, &c.Name, &c.Description, &c.DeletedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -48,9 +56,12 @@ func (repo *categoryRepository) Create(category *model.Category) error {
 }
 
 func (repo *categoryRepository) GetByID(id int) (*model.Category, error) {
-	query := "SELECT id, name, description FROM categories WHERE id = $1"
+	query := "SELECT id, name, description FROM categories WHERE id = $1 AND deleted_at IS NULL"
 	var c model.Category
 	err := repo.db.QueryRow(query, id).Scan(&c.Id, &c.Name, &c.Description)
+	if err == sql.ErrNoRows {
+		return nil, ErrCategoryNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -58,13 +69,56 @@ func (repo *categoryRepository) GetByID(id int) (*model.Category, error) {
 }
 
 func (repo *categoryRepository) Update(category *model.Category) error {
-	query := "UPDATE categories SET name = $1, description = $2 WHERE id = $3"
-	_, err := repo.db.Exec(query, category.Name, category.Description, category.Id)
-	return err
+	query := "UPDATE categories SET name = $1, description = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3 AND deleted_at IS NULL"
+	result, err := repo.db.Exec(query, category.Name, category.Description, category.Id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
 }
 
 func (repo *categoryRepository) Delete(id int) error {
-	query := "DELETE FROM categories WHERE id = $1"
-	_, err := repo.db.Exec(query, id)
-	return err
-}
\ No newline at end of file
+	query := "UPDATE categories SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL"
+	result, err := repo.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (repo *categoryRepository) Restore(id int) error {
+	query := "UPDATE categories SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL"
+	result, err := repo.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (repo *categoryRepository) HasActiveProducts(id int) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM products WHERE category_id = $1 AND deleted_at IS NULL)"
+	var exists bool
+	err := repo.db.QueryRow(query, id).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrCustomerNotFound    = errors.New("pelanggan tidak ditemukan")
+	ErrCustomerPhoneExists = errors.New("nomor telepon sudah terdaftar")
+)
+
+type CustomerRepositoryInterface interface {
+	GetAll(search string) ([]model.Customer, error)
+	Create(customer *model.Customer) error
+	GetByID(id int) (*model.Customer, error)
+	Update(customer *model.Customer) error
+	Delete(id int) error
+}
+
+type customerRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerRepository(db *sql.DB) CustomerRepositoryInterface {
+	return &customerRepository{db: db}
+}
+
+func (repo *customerRepository) GetAll(search string) ([]model.Customer, error) {
+	query := "SELECT id, name, phone, COALESCE(email, ''), COALESCE(address, ''), created_at, updated_at FROM customers WHERE deleted_at IS NULL"
+	args := []interface{}{}
+	if search != "" {
+		args = append(args, "%"+search+"%")
+		query += " AND (name ILIKE $1 OR phone LIKE $1)"
+	}
+	query += " ORDER BY name"
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := make([]model.Customer, 0)
+	for rows.Next() {
+		var c model.Customer
+		err := rows.Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Address, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	return customers, nil
+}
+
+func (repo *customerRepository) Create(customer *model.Customer) error {
+	query := "INSERT INTO customers (name, phone, email, address) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')) RETURNING id, created_at, updated_at"
+	err := repo.db.QueryRow(query, customer.Name, customer.Phone, customer.Email, customer.Address).Scan(&customer.ID, &customer.CreatedAt, &customer.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrCustomerPhoneExists
+	}
+	return err
+}
+
+func (repo *customerRepository) GetByID(id int) (*model.Customer, error) {
+	query := "SELECT id, name, phone, COALESCE(email, ''), COALESCE(address, ''), created_at, updated_at FROM customers WHERE id = $1 AND deleted_at IS NULL"
+	var c model.Customer
+	err := repo.db.QueryRow(query, id).Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Address, &c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (repo *customerRepository) Update(customer *model.Customer) error {
+	query := "UPDATE customers SET name = $1, phone = $2, email = NULLIF($3, ''), address = NULLIF($4, ''), updated_at = CURRENT_TIMESTAMP WHERE id = $5 AND deleted_at IS NULL RETURNING created_at, updated_at"
+	err := repo.db.QueryRow(query, customer.Name, customer.Phone, customer.Email, customer.Address, customer.ID).Scan(&customer.CreatedAt, &customer.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrCustomerNotFound
+	}
+	if isUniqueViolation(err) {
+		return ErrCustomerPhoneExists
+	}
+	return err
+}
+
+func (repo *customerRepository) Delete(id int) error {
+	query := "UPDATE customers SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL"
+	result, err := repo.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCustomerNotFound
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,27 @@
+package repository
+
+import "database/sql"
+
+type InvoiceRepositoryInterface interface {
+	NextNumber(tx *sql.Tx, storeCode string, period string) (int, error)
+}
+
+type invoiceRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) InvoiceRepositoryInterface {
+	return &invoiceRepository{db: db}
+}
+
+// NextNumber increments the sequence inside the caller's transaction. The row
+// stays locked until the transaction ends and a rollback gives the number
+// back, so committed invoices never leave gaps.
+func (repo *invoiceRepository) NextNumber(tx *sql.Tx, storeCode string, period string) (int, error) {
+	query := `INSERT INTO invoice_sequences (store_code, period, last_number) VALUES ($1, $2, 1)
+		ON CONFLICT (store_code, period) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number`
+	var number int
+	err := tx.QueryRow(query, storeCode, period).Scan(&number)
+	return number, err
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+)
+
+var (
+	ErrLocationNotFound   = errors.New("lokasi tidak ditemukan")
+	ErrLocationCodeExists = errors.New("kode lokasi sudah dipakai")
+)
+
+type LocationRepositoryInterface interface {
+	GetAll() ([]model.Location, error)
+	Create(location *model.Location) error
+	GetByID(id int) (*model.Location, error)
+	GetByCode(code string) (*model.Location, error)
+	Update(location *model.Location) error
+}
+
+type locationRepository struct {
+	db *sql.DB
+}
+
+func NewLocationRepository(db *sql.DB) LocationRepositoryInterface {
+	return &locationRepository{db: db}
+}
+
+func (repo *locationRepository) GetAll() ([]model.Location, error) {
+	query := "SELECT id, code, name, type, address, created_at, updated_at FROM locations ORDER BY code"
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := make([]model.Location, 0)
+	for rows.Next() {
+		var l model.Location
+		err := rows.Scan(&l.ID, &l.Code, &l.Name, &l.Type, &l.Address, &l.CreatedAt, &l.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+	return locations, rows.Err()
+}
+
+func (repo *locationRepository) Create(location *model.Location) error {
+	query := "INSERT INTO locations (code, name, type, address) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at"
+	err := repo.db.QueryRow(query, location.Code, location.Name, location.Type, location.Address).Scan(&location.ID, &location.CreatedAt, &location.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrLocationCodeExists
+	}
+	return err
+}
+
+func (repo *locationRepository) GetByID(id int) (*model.Location, error) {
+	query := "SELECT id, code, name, type, address, created_at, updated_at FROM locations WHERE id = $1"
+	return scanLocation(repo.db.QueryRow(query, id))
+}
+
+func (repo *locationRepository) GetByCode(code string) (*model.Location, error) {
+	query := "SELECT id, code, name, type, address, created_at, updated_at FROM locations WHERE code = $1"
+	return scanLocation(repo.db.QueryRow(query, code))
+}
+
+func (repo *locationRepository) Update(location *model.Location) error {
+	query := "UPDATE locations SET code = $1, name = $2, type = $3, address = $4, updated_at = CURRENT_TIMESTAMP WHERE id = $5 RETURNING created_at, updated_at"
+	err := repo.db.QueryRow(query, location.Code, location.Name, location.Type, location.Address, location.ID).Scan(&location.CreatedAt, &location.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrLocationNotFound
+	}
+	if isUniqueViolation(err) {
+		return ErrLocationCodeExists
+	}
+	return err
+}
+
+func scanLocation(row *sql.Row) (*model.Location, error) {
+	var l model.Location
+	err := row.Scan(&l.ID, &l.Code, &l.Name, &l.Type, &l.Address, &l.CreatedAt, &l.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrLocationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+	"product-api/model"
+)
+
+type LoyaltyRepositoryInterface interface {
+	BeginTrans() (*sql.Tx, error)
+	CommitTrans(tx *sql.Tx) error
+	RollbackTrans(tx *sql.Tx) error
+	Create(tx *sql.Tx, entry *model.PointEntry) error
+	GetBalance(tx *sql.Tx, customerID int) (int, error)
+	GetExpiredPoints(tx *sql.Tx, customerID int) (int, error)
+	ReadBalance(customerID int) (int, int, error)
+	GetByCustomerID(customerID int) ([]model.PointEntry, error)
+	GetByTransactionID(tx *sql.Tx, transactionID int) ([]model.PointEntry, error)
+}
+
+type loyaltyRepository struct {
+	db *sql.DB
+}
+
+func NewLoyaltyRepository(db *sql.DB) LoyaltyRepositoryInterface {
+	return &loyaltyRepository{db: db}
+}
+
+func (repo *loyaltyRepository) BeginTrans() (*sql.Tx, error) {
+	return repo.db.Begin()
+}
+
+func (repo *loyaltyRepository) CommitTrans(tx *sql.Tx) error {
+	return tx.Commit()
+}
+
+func (repo *loyaltyRepository) RollbackTrans(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+func (repo *loyaltyRepository) Create(tx *sql.Tx, entry *model.PointEntry) error {
+	query := "INSERT INTO loyalty_points (customer_id, transaction_id, type, points, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at"
+	return tx.QueryRow(query, entry.CustomerID, entry.TransactionID, entry.Type, entry.Points, entry.ExpiresAt).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// GetBalance locks the customer row so concurrent checkouts cannot spend the
+// same points twice, then sums the ledger.
+func (repo *loyaltyRepository) GetBalance(tx *sql.Tx, customerID int) (int, error) {
+	var id int
+	err := tx.QueryRow("SELECT id FROM customers WHERE id = $1 FOR UPDATE", customerID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrCustomerNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var balance int
+	err = tx.QueryRow("SELECT COALESCE(SUM(points), 0) FROM loyalty_points WHERE customer_id = $1", customerID).Scan(&balance)
+	return balance, err
+}
+
+// pointTotalsQuery sums the ledger, the points that have passed their expiry
+// date and the points consumed by redemptions or earlier expire entries.
+const pointTotalsQuery = `SELECT
+	COALESCE(SUM(points), 0),
+	COALESCE(SUM(points) FILTER (WHERE expires_at <= CURRENT_TIMESTAMP AND (type = 'earn' OR (type = 'refund' AND points < 0))), 0),
+	COALESCE(-SUM(points) FILTER (WHERE type IN ('redeem', 'expire') OR (type = 'refund' AND points > 0)), 0)
+	FROM loyalty_points WHERE customer_id = $1`
+
+// GetExpiredPoints returns how many points have passed their expiry date and
+// are not yet covered by redemptions or earlier expire entries. Redemptions
+// are assumed to consume the oldest points first.
+func (repo *loyaltyRepository) GetExpiredPoints(tx *sql.Tx, customerID int) (int, error) {
+	var balance, matured, consumed int
+	err := tx.QueryRow(pointTotalsQuery, customerID).Scan(&balance, &matured, &consumed)
+	if err != nil {
+		return 0, err
+	}
+	return expiredPoints(matured, consumed), nil
+}
+
+// ReadBalance returns the ledger balance and the expired points not yet
+// written off, like GetBalance and GetExpiredPoints, without locking the
+// customer. Use it for display only.
+func (repo *loyaltyRepository) ReadBalance(customerID int) (int, int, error) {
+	var exists bool
+	err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM customers WHERE id = $1)", customerID).Scan(&exists)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !exists {
+		return 0, 0, ErrCustomerNotFound
+	}
+
+	var balance, matured, consumed int
+	err = repo.db.QueryRow(pointTotalsQuery, customerID).Scan(&balance, &matured, &consumed)
+	if err != nil {
+		return 0, 0, err
+	}
+	return balance, expiredPoints(matured, consumed), nil
+}
+
+func expiredPoints(matured int, consumed int) int {
+	if matured <= consumed {
+		return 0
+	}
+	return matured - consumed
+}
+
+func (repo *loyaltyRepository) GetByCustomerID(customerID int) ([]model.PointEntry, error) {
+	query := "SELECT id, customer_id, transaction_id, type, points, expires_at, created_at FROM loyalty_points WHERE customer_id = $1 ORDER BY created_at DESC, id DESC"
+	rows, err := repo.db.Query(query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return scanPointEntries(rows)
+}
+
+func (repo *loyaltyRepository) GetByTransactionID(tx *sql.Tx, transactionID int) ([]model.PointEntry, error) {
+	query := "SELECT id, customer_id, transaction_id, type, points, expires_at, created_at FROM loyalty_points WHERE transaction_id = $1 ORDER BY id"
+	rows, err := tx.Query(query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return scanPointEntries(rows)
+}
+
+func scanPointEntries(rows *sql.Rows) ([]model.PointEntry, error) {
+	defer rows.Close()
+
+	entries := make([]model.PointEntry, 0)
+	for rows.Next() {
+		var e model.PointEntry
+		err := rows.Scan(&e.ID, &e.CustomerID, &e.TransactionID, &e.Type, &e.Points, &e.ExpiresAt, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
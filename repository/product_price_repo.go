@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+)
+
+var ErrProductPriceNotFound = errors.New("jadwal harga tidak ditemukan")
+
+type ProductPriceRepositoryInterface interface {
+	Create(tx *sql.Tx, price *model.ProductPrice) error
+	GetByProductID(productID int) ([]model.ProductPrice, error)
+	DeleteScheduled(productID int, id int) error
+}
+
+type productPriceRepository struct {
+	db *sql.DB
+}
+
+func NewProductPriceRepository(db *sql.DB) ProductPriceRepositoryInterface {
+	return &productPriceRepository{db: db}
+}
+
+func (repo *productPriceRepository) Create(tx *sql.Tx, price *model.ProductPrice) error {
+	query := "INSERT INTO product_prices (product_id, price, effective_from) VALUES ($1, $2, COALESCE($3, CURRENT_TIMESTAMP)) RETURNING id, effective_from, created_at"
+	var effectiveFrom interface{}
+	if !price.EffectiveFrom.IsZero() {
+		effectiveFrom = price.EffectiveFrom
+	}
+	return tx.QueryRow(query, price.ProductID, price.Price, effectiveFrom).Scan(&price.ID, &price.EffectiveFrom, &price.CreatedAt)
+}
+
+func (repo *productPriceRepository) GetByProductID(productID int) ([]model.ProductPrice, error) {
+	query := "SELECT id, product_id, price, effective_from, created_at FROM product_prices WHERE product_id = $1 ORDER BY effective_from DESC, id DESC"
+	rows, err := repo.db.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make([]model.ProductPrice, 0)
+	for rows.Next() {
+		var p model.ProductPrice
+		err := rows.Scan(&p.ID, &p.ProductID, &p.Price, &p.EffectiveFrom, &p.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+	return prices, nil
+}
+
+func (repo *productPriceRepository) DeleteScheduled(productID int, id int) error {
+	query := "DELETE FROM product_prices WHERE id = $1 AND product_id = $2 AND effective_from > CURRENT_TIMESTAMP"
+	result, err := repo.db.Exec(query, id, productID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrProductPriceNotFound
+	}
+	return nil
+}
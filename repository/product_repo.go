@@ -6,18 +6,28 @@ import (
 	"product-api/model"
 )
 
+var ErrProductNotFound = errors.New("produk tidak ditemukan")
+
+// effectivePrice resolves the price from the latest product_prices entry that
+// has already taken effect, falling back to the price stored on the product.
+const effectivePrice = "COALESCE((SELECT pp.price FROM product_prices pp WHERE pp.product_id = products.id AND pp.effective_from <= CURRENT_TIMESTAMP ORDER BY pp.effective_from DESC, pp.id DESC LIMIT 1), products.price)"
+
+// reservedStock sums the unexpired stock reservations held on the product.
+const reservedStock = "COALESCE((SELECT SUM(sr.quantity) FROM stock_reservations sr WHERE sr.product_id = products.id AND sr.expires_at > CURRENT_TIMESTAMP), 0)"
+
 type ProductRepositoryInterface interface {
 	BeginTrans() (*sql.Tx, error)
 	CommitTrans(tx *sql.Tx) error
 	RollbackTrans(tx *sql.Tx) error
-	GetAll(name string) ([]model.Product, error)
-	Create(product *model.Product) error
+	GetAll(name string, includeDeleted bool) ([]model.Product, error)
+	Create(tx *sql.Tx, product *model.Product) error
 	GetByID(id int) (*model.Product, error)
-	Update( tx *sql.Tx, product *model.Product) error
+	LockByID(tx *sql.Tx, id int) (*model.Product, error)
+	Update(tx *sql.Tx, product *model.Product) error
 	Delete(id int) error
+	Restore(tx *sql.Tx, id int) (*model.Product, error)
 }
 
-
 type productRepository struct {
 	db *sql.DB
 }
@@ -26,7 +36,6 @@ func NewProductRepository(db *sql.DB) ProductRepositoryInterface {
 	return &productRepository{db: db}
 }
 
-
 func (repo *productRepository) BeginTrans() (*sql.Tx, error) {
 	return repo.db.Begin()
 }
@@ -39,12 +48,17 @@ func (repo *productRepository) RollbackTrans(tx *sql.Tx) error {
 	return tx.Rollback()
 }
 
-func (repo *productRepository) GetAll(name string) ([]model.Product, error) {
-	query := "SELECT id, name, price, stock, category_id FROM products"
+func (repo *productRepository) GetAll(name string, includeDeleted bool) ([]model.Product, error) {
+	query := "SELECT id, name, " + effectivePrice + ", stock, " + reservedStock + ", category_id, deleted_at FROM products WHERE 1 = 1"
+	args := []interface{}{}
 	if name != "" {
-		query += " WHERE name ILIKE '%" + name + "%'"
+		args = append(args, "%"+name+"%")
+		query += " AND name ILIKE $1"
+	}
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
 	}
-	rows, err := repo.db.Query(query)
+	rows, err := repo.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -53,30 +67,49 @@ func (repo *productRepository) GetAll(name string) ([]model.Product, error) {
 	products := make([]model.Product, 0)
 	for rows.Next() {
 		var p model.Product
-		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID)
+		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.Reserved, &p.CategoryID, &p.DeletedAt)
 		if err != nil {
 			return nil, err
 		}
+		p.Available = p.Stock - p.Reserved
 		products = append(products, p)
 	}
 
 	return products, nil
 }
 
-func (repo *productRepository) Create(product *model.Product) error {
+func (repo *productRepository) Create(tx *sql.Tx, product *model.Product) error {
 	query := "INSERT INTO products (name, price, stock, category_id) VALUES ($1, $2, $3, $4) RETURNING id"
-	err := repo.db.QueryRow(query, product.Name, product.Price, product.Stock, product.CategoryID).Scan(&product.ID)
+	err := tx.QueryRow(query, product.Name, product.Price, product.Stock, product.CategoryID).Scan(&product.ID)
 	return err
 }
 
-
 func (repo *productRepository) GetByID(id int) (*model.Product, error) {
-	query := "SELECT id, name, price, stock, category_id FROM products WHERE id = $1"
+	query := "SELECT id, name, " + effectivePrice + ", stock, " + reservedStock + ", category_id FROM products WHERE id = $1 AND deleted_at IS NULL"
+
+	var p model.Product
+	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.Reserved, &p.CategoryID)
+	if err == sql.ErrNoRows {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Available = p.Stock - p.Reserved
+
+	return &p, nil
+}
+
+// LockByID reads an active product with a row lock. Everything that checks
+// stock against reservations locks the product first, so the available
+// quantity cannot be handed out twice.
+func (repo *productRepository) LockByID(tx *sql.Tx, id int) (*model.Product, error) {
+	query := "SELECT id, name, " + effectivePrice + ", stock, category_id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
 
 	var p model.Product
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID)
+	err := tx.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID)
 	if err == sql.ErrNoRows {
-		return nil, errors.New("produk tidak ditemukan")
+		return nil, ErrProductNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -85,8 +118,8 @@ func (repo *productRepository) GetByID(id int) (*model.Product, error) {
 	return &p, nil
 }
 
-func (repo *productRepository) Update( tx *sql.Tx, product *model.Product) error {
-	query := "UPDATE products SET name = $1, price = $2, stock = $3, category_id = $4 WHERE id = $5"
+func (repo *productRepository) Update(tx *sql.Tx, product *model.Product) error {
+	query := "UPDATE products SET name = $1, price = $2, stock = $3, category_id = $4, updated_at = CURRENT_TIMESTAMP WHERE id = $5 AND deleted_at IS NULL"
 	result, err := tx.Exec(query, product.Name, product.Price, product.Stock, product.CategoryID, product.ID)
 	if err != nil {
 		return err
@@ -98,14 +131,14 @@ func (repo *productRepository) Update( tx *sql.Tx, product *model.Product) error
 	}
 
 	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+		return ErrProductNotFound
 	}
 
 	return nil
 }
 
 func (repo *productRepository) Delete(id int) error {
-	query := "DELETE FROM products WHERE id = $1"
+	query := "UPDATE products SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL"
 	result, err := repo.db.Exec(query, id)
 	if err != nil {
 		return err
@@ -116,9 +149,24 @@ func (repo *productRepository) Delete(id int) error {
 	}
 
 	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+		return ErrProductNotFound
 	}
 
 	return err
 }
 
+func (repo *productRepository) Restore(tx *sql.Tx, id int) (*model.Product, error) {
+	query := "UPDATE products SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING id, name, " + effectivePrice + ", stock, " + reservedStock + ", category_id"
+
+	var p model.Product
+	err := tx.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.Reserved, &p.CategoryID)
+	if err == sql.ErrNoRows {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Available = p.Stock - p.Reserved
+
+	return &p, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+)
+
+var ErrReceiptTemplateNotFound = errors.New("template struk tidak ditemukan")
+
+type ReceiptRepositoryInterface interface {
+	GetSettings() (*model.ReceiptSettings, error)
+	UpdateSettings(settings *model.ReceiptSettings) error
+	GetTemplate(format string) (*model.ReceiptTemplate, error)
+	SaveTemplate(template *model.ReceiptTemplate) error
+	DeleteTemplate(format string) error
+}
+
+type receiptRepository struct {
+	db *sql.DB
+}
+
+func NewReceiptRepository(db *sql.DB) ReceiptRepositoryInterface {
+	return &receiptRepository{db: db}
+}
+
+func (repo *receiptRepository) GetSettings() (*model.ReceiptSettings, error) {
+	query := "SELECT store_name, address, phone, footer, updated_at FROM receipt_settings WHERE id = 1"
+	var s model.ReceiptSettings
+	err := repo.db.QueryRow(query).Scan(&s.StoreName, &s.Address, &s.Phone, &s.Footer, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return &model.ReceiptSettings{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (repo *receiptRepository) UpdateSettings(settings *model.ReceiptSettings) error {
+	query := `INSERT INTO receipt_settings (id, store_name, address, phone, footer) VALUES (1, $1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET store_name = $1, address = $2, phone = $3, footer = $4, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at`
+	return repo.db.QueryRow(query, settings.StoreName, settings.Address, settings.Phone, settings.Footer).Scan(&settings.UpdatedAt)
+}
+
+func (repo *receiptRepository) GetTemplate(format string) (*model.ReceiptTemplate, error) {
+	query := "SELECT format, content, updated_at FROM receipt_templates WHERE format = $1"
+	var t model.ReceiptTemplate
+	err := repo.db.QueryRow(query, format).Scan(&t.Format, &t.Content, &t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrReceiptTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (repo *receiptRepository) SaveTemplate(template *model.ReceiptTemplate) error {
+	query := `INSERT INTO receipt_templates (format, content) VALUES ($1, $2)
+		ON CONFLICT (format) DO UPDATE SET content = $2, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at`
+	return repo.db.QueryRow(query, template.Format, template.Content).Scan(&template.UpdatedAt)
+}
+
+func (repo *receiptRepository) DeleteTemplate(format string) error {
+	query := "DELETE FROM receipt_templates WHERE format = $1"
+	_, err := repo.db.Exec(query, format)
+	return err
+}
@@ -0,0 +1,483 @@
+package repository
+
+import (
+	"database/sql"
+	"product-api/model"
+	"product-api/utils/period"
+	"time"
+)
+
+type ReportRepositoryInterface interface {
+	Summary(filter model.ReportFilter) (model.SummaryResponse, error)
+	Timeseries(filter model.ReportFilter, interval string) ([]model.TimeseriesPoint, error)
+	TopProducts(filter model.ReportFilter, by string, limit int) (*model.TopResponse, error)
+	TopCategories(filter model.ReportFilter, by string, limit int) (*model.TopResponse, error)
+	SalesByProduct(filter model.ReportFilter) ([]model.BreakdownRow, error)
+	ProductPairs(filter model.ReportFilter, minTransactions int, limit int) (*model.BasketAnalysis, error)
+	RelatedProducts(productID int, filter model.ReportFilter, limit int) (*model.RelatedProducts, error)
+	Inventory(locationID int, salesFrom string, salesTo string) ([]model.InventoryRow, error)
+}
+
+// topOrder ranks by the chosen metric, then by the other one, then by name
+// and id so ties always come out in the same order.
+var topOrder = map[string]string{
+	model.ReportRankByQuantity: "quantity DESC, revenue DESC, name, id",
+	model.ReportRankByRevenue:  "revenue DESC, quantity DESC, name, id",
+}
+
+// bookedTransactions books transactions the way the daily rollups do: a sale
+// with sign 1 at its created_at and a refund with sign -1 at its refunded_at.
+// It selects the entries booked between $1 and $2 at location $3, or at all
+// locations when $3 is 0, with the time they were booked at as booked_at.
+const bookedTransactions = `(
+		SELECT t.*, 1 AS sign, t.created_at AS booked_at FROM transactions t
+		WHERE t.created_at >= $1 AND t.created_at < $2 AND ($3 = 0 OR t.location_id = $3)
+		UNION ALL
+		SELECT t.*, -1, t.refunded_at FROM transactions t
+		WHERE t.refunded_at >= $1 AND t.refunded_at < $2 AND ($3 = 0 OR t.location_id = $3)
+	)`
+
+// productSalesQuery and productRollupQuery sum the quantity and revenue of
+// every product sold between $1 and $2 at location $3, or at all locations
+// when $3 is 0, less the refunds made in that range. Products without net
+// sales are left out.
+const productSalesQuery = `SELECT p.id, p.name, SUM(t.sign * td.quantity), SUM(t.sign * td.subtotal)
+	FROM ` + bookedTransactions + ` t
+	JOIN transaction_details td ON td.transaction_id = t.id
+	JOIN products p ON p.id = td.product_id
+	GROUP BY p.id, p.name
+	HAVING SUM(t.sign * td.quantity) > 0`
+
+const productRollupQuery = `SELECT p.id, p.name, SUM(ds.quantity), SUM(ds.gross_sales)
+	FROM daily_sales ds
+	JOIN products p ON p.id = ds.product_id
+	WHERE ds.sale_date >= $1 AND ds.sale_date < $2 AND ($3 = 0 OR ds.location_id = $3)
+	GROUP BY p.id, p.name
+	HAVING SUM(ds.quantity) > 0`
+
+type reportRepository struct {
+	db       *sql.DB
+	timezone *time.Location
+}
+
+// NewReportRepository creates the report repository. Reports over whole days
+// in timezone, the one the daily rollups are cut in, are read from the
+// rollups; other ranges fall back to the transactions.
+func NewReportRepository(db *sql.DB, timezone *time.Location) ReportRepositoryInterface {
+	return &reportRepository{db: db, timezone: timezone}
+}
+
+// rollupRange returns the dates of the daily rollups covering the filter, or
+// false when the filter does not consist of whole days in the rollup
+// timezone.
+func (repo *reportRepository) rollupRange(filter model.ReportFilter) (string, string, bool) {
+	if filter.Timezone.String() != repo.timezone.String() {
+		return "", "", false
+	}
+	from := filter.From.In(repo.timezone)
+	to := filter.To.In(repo.timezone)
+	if !from.Equal(period.StartOfDay(from, repo.timezone)) || !to.Equal(period.StartOfDay(to, repo.timezone)) {
+		return "", "", false
+	}
+	return from.Format(period.DateLayout), to.Format(period.DateLayout), true
+}
+
+// bounds returns the first two arguments of the sales queries: the dates of
+// the rollups when they can be used, otherwise the timestamps of the filter.
+func (repo *reportRepository) bounds(filter model.ReportFilter) (interface{}, interface{}, bool) {
+	from, to, ok := repo.rollupRange(filter)
+	if ok {
+		return from, to, true
+	}
+	return filter.From, filter.To, false
+}
+
+// Summary totals the transactions of the filter less the refunds made in it
+// and picks the product sold most, ties going to the alphabetically first
+// name.
+func (repo *reportRepository) Summary(filter model.ReportFilter) (model.SummaryResponse, error) {
+	totalsQuery := `SELECT COALESCE(SUM(t.sign * t.total_amount), 0), COALESCE(SUM(t.sign), 0) FROM ` + bookedTransactions + ` t`
+	productsQuery := productSalesQuery
+	from, to, rollup := repo.bounds(filter)
+	if rollup {
+		totalsQuery = `SELECT COALESCE(SUM(revenue), 0), COALESCE(SUM(transaction_count), 0) FROM daily_transactions
+			WHERE sale_date >= $1 AND sale_date < $2 AND ($3 = 0 OR location_id = $3)`
+		productsQuery = productRollupQuery
+	}
+
+	var summary model.SummaryResponse
+	err := repo.db.QueryRow(totalsQuery, from, to, filter.LocationID).Scan(&summary.TotalRevenue, &summary.TotalTransaction)
+	if err != nil {
+		return model.SummaryResponse{}, err
+	}
+	query := `SELECT name, SUM(quantity) FROM (` + productsQuery + `) AS sales (id, name, quantity, revenue)
+		GROUP BY name ORDER BY 2 DESC, name LIMIT 1`
+	err = repo.db.QueryRow(query, from, to, filter.LocationID).Scan(&summary.ProductTerlaris.Name, &summary.ProductTerlaris.QtyTerjual)
+	if err != nil && err != sql.ErrNoRows {
+		return model.SummaryResponse{}, err
+	}
+	return summary, nil
+}
+
+// Timeseries buckets the transactions of the filter by interval in the
+// filter's timezone, with refunds taken out of the bucket they were made in.
+// Every bucket of the range is returned, empty ones with zeros. Hourly
+// buckets always come from the transactions.
+func (repo *reportRepository) Timeseries(filter model.ReportFilter, interval string) ([]model.TimeseriesPoint, error) {
+	query := `WITH buckets AS (
+			SELECT generate_series(
+				date_trunc($4, $1::timestamptz AT TIME ZONE $5),
+				date_trunc($4, ($2::timestamptz - INTERVAL '1 microsecond') AT TIME ZONE $5),
+				('1 ' || $4)::interval
+			) AS bucket
+		),
+		sales AS (
+			SELECT date_trunc($4, t.booked_at AT TIME ZONE $5) AS bucket,
+				SUM(t.sign) AS transaction_count,
+				SUM(t.sign * t.total_amount) AS revenue,
+				SUM(t.sign * COALESCE(d.items_sold, 0)) AS items_sold
+			FROM ` + bookedTransactions + ` t
+			LEFT JOIN (
+				SELECT transaction_id, SUM(quantity) AS items_sold FROM transaction_details GROUP BY transaction_id
+			) d ON d.transaction_id = t.id
+			GROUP BY 1
+		)
+		SELECT b.bucket AT TIME ZONE $5, COALESCE(s.revenue, 0), COALESCE(s.transaction_count, 0), COALESCE(s.items_sold, 0)
+		FROM buckets b LEFT JOIN sales s ON s.bucket = b.bucket
+		ORDER BY b.bucket`
+	from, to, rollup := repo.bounds(filter)
+	if rollup && interval != model.ReportIntervalHour {
+		query = `WITH buckets AS (
+				SELECT generate_series(
+					date_trunc($4, $1::date::timestamp),
+					date_trunc($4, ($2::date - 1)::timestamp),
+					('1 ' || $4)::interval
+				) AS bucket
+			),
+			sales AS (
+				SELECT date_trunc($4, sale_date::timestamp) AS bucket,
+					SUM(transaction_count) AS transaction_count,
+					SUM(revenue) AS revenue,
+					SUM(items_sold) AS items_sold
+				FROM daily_transactions
+				WHERE sale_date >= $1 AND sale_date < $2 AND ($3 = 0 OR location_id = $3)
+				GROUP BY 1
+			)
+			SELECT b.bucket AT TIME ZONE $5, COALESCE(s.revenue, 0), COALESCE(s.transaction_count, 0), COALESCE(s.items_sold, 0)
+			FROM buckets b LEFT JOIN sales s ON s.bucket = b.bucket
+			ORDER BY b.bucket`
+	} else {
+		from, to = filter.From, filter.To
+	}
+	rows, err := repo.db.Query(query, from, to, filter.LocationID, interval, filter.Timezone.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make([]model.TimeseriesPoint, 0)
+	for rows.Next() {
+		var point model.TimeseriesPoint
+		err := rows.Scan(&point.Bucket, &point.Revenue, &point.TransactionCount, &point.ItemsSold)
+		if err != nil {
+			return nil, err
+		}
+		point.Bucket = point.Bucket.In(filter.Timezone)
+		if point.TransactionCount > 0 {
+			point.AverageBasket = point.Revenue / point.TransactionCount
+		}
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
+
+// TopProducts ranks the products sold in the filter's range. The totals cover
+// all products, not only the returned ones.
+func (repo *reportRepository) TopProducts(filter model.ReportFilter, by string, limit int) (*model.TopResponse, error) {
+	return repo.top(productSalesQuery, productRollupQuery, filter, by, limit)
+}
+
+// TopCategories ranks categories by the sales of their products in the
+// filter's range.
+func (repo *reportRepository) TopCategories(filter model.ReportFilter, by string, limit int) (*model.TopResponse, error) {
+	salesQuery := `SELECT c.id, c.name, SUM(t.sign * td.quantity), SUM(t.sign * td.subtotal)
+		FROM ` + bookedTransactions + ` t
+		JOIN transaction_details td ON td.transaction_id = t.id
+		JOIN products p ON p.id = td.product_id
+		JOIN categories c ON c.id = p.category_id
+		GROUP BY c.id, c.name
+		HAVING SUM(t.sign * td.quantity) > 0`
+	rollupQuery := `SELECT c.id, c.name, SUM(ds.quantity), SUM(ds.gross_sales)
+		FROM daily_sales ds
+		JOIN products p ON p.id = ds.product_id
+		JOIN categories c ON c.id = p.category_id
+		WHERE ds.sale_date >= $1 AND ds.sale_date < $2 AND ($3 = 0 OR ds.location_id = $3)
+		GROUP BY c.id, c.name
+		HAVING SUM(ds.quantity) > 0`
+	return repo.top(salesQuery, rollupQuery, filter, by, limit)
+}
+
+func (repo *reportRepository) top(salesQuery string, rollupQuery string, filter model.ReportFilter, by string, limit int) (*model.TopResponse, error) {
+	from, to, rollup := repo.bounds(filter)
+	if rollup {
+		salesQuery = rollupQuery
+	}
+	query := `SELECT id, name, quantity, revenue, SUM(quantity) OVER (), SUM(revenue) OVER ()
+		FROM (` + salesQuery + `) AS sales (id, name, quantity, revenue)
+		ORDER BY ` + topOrder[by] + ` LIMIT $4`
+	rows, err := repo.db.Query(query, from, to, filter.LocationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	response := model.TopResponse{By: by, From: filter.From, To: filter.To, Items: make([]model.TopItem, 0)}
+	for rows.Next() {
+		var item model.TopItem
+		err := rows.Scan(&item.ID, &item.Name, &item.Quantity, &item.Revenue, &response.TotalQuantity, &response.TotalRevenue)
+		if err != nil {
+			return nil, err
+		}
+		response.Items = append(response.Items, item)
+	}
+	return &response, rows.Err()
+}
+
+// SalesByProduct sums the sales of every product sold in the filter's range,
+// less the refunds made in it. The discount and tax of a transaction are
+// spread over its lines in proportion to their subtotal; the cost comes from
+// the batches sold.
+func (repo *reportRepository) SalesByProduct(filter model.ReportFilter) ([]model.BreakdownRow, error) {
+	query := `WITH lines AS (
+			SELECT td.product_id, t.sign * td.quantity AS quantity, t.sign * td.subtotal AS subtotal,
+				t.sign * td.subtotal::numeric * t.discount_amount / NULLIF(t.total_amount + t.discount_amount, 0) AS discount,
+				t.sign * td.subtotal::numeric * t.tax_amount / NULLIF(t.total_amount + t.discount_amount, 0) AS tax,
+				t.sign * COALESCE(cost.quantity, 0) AS cost_quantity,
+				t.sign * COALESCE(cost.amount, 0) AS cost_amount
+			FROM ` + bookedTransactions + ` t
+			JOIN transaction_details td ON td.transaction_id = t.id
+			LEFT JOIN (
+				SELECT db.transaction_detail_id, SUM(db.quantity) AS quantity, SUM(db.quantity * b.unit_cost) AS amount
+				FROM transaction_detail_batches db JOIN product_batches b ON b.id = db.batch_id
+				GROUP BY db.transaction_detail_id
+			) cost ON cost.transaction_detail_id = td.id
+		)
+		SELECT p.id, p.name, COALESCE(c.id, 0), COALESCE(c.name, ''),
+			SUM(l.quantity), SUM(l.subtotal),
+			ROUND(COALESCE(SUM(l.discount), 0))::int, ROUND(COALESCE(SUM(l.tax), 0))::int,
+			SUM(l.cost_quantity), SUM(l.cost_amount)
+		FROM lines l
+		JOIN products p ON p.id = l.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		GROUP BY p.id, p.name, c.id, c.name
+		HAVING SUM(l.quantity) > 0
+		ORDER BY p.name, p.id`
+	from, to, rollup := repo.bounds(filter)
+	if rollup {
+		query = `SELECT p.id, p.name, COALESCE(c.id, 0), COALESCE(c.name, ''),
+				SUM(ds.quantity), SUM(ds.gross_sales),
+				ROUND(SUM(ds.discount_amount))::int, ROUND(SUM(ds.tax_amount))::int,
+				SUM(ds.cost_quantity), SUM(ds.cost_amount)
+			FROM daily_sales ds
+			JOIN products p ON p.id = ds.product_id
+			LEFT JOIN categories c ON c.id = p.category_id
+			WHERE ds.sale_date >= $1 AND ds.sale_date < $2 AND ($3 = 0 OR ds.location_id = $3)
+			GROUP BY p.id, p.name, c.id, c.name
+			HAVING SUM(ds.quantity) > 0
+			ORDER BY p.name, p.id`
+	}
+	rows, err := repo.db.Query(query, from, to, filter.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.BreakdownRow, 0)
+	for rows.Next() {
+		var row model.BreakdownRow
+		err := rows.Scan(&row.ID, &row.Name, &row.CategoryID, &row.CategoryName, &row.Quantity, &row.GrossSales, &row.Discount, &row.Tax, &row.CostQuantity, &row.CostAmount)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// basketsQuery holds the distinct products of every non-refunded transaction
+// of the filter, with the number of transactions per product and in total.
+const basketsQuery = `baskets AS (
+		SELECT DISTINCT td.transaction_id, td.product_id
+		FROM transaction_details td
+		JOIN transactions t ON t.id = td.transaction_id
+		WHERE t.created_at >= $1 AND t.created_at < $2 AND t.refunded_at IS NULL
+			AND ($3 = 0 OR t.location_id = $3)
+	),
+	items AS (
+		SELECT product_id, COUNT(*) AS transactions FROM baskets GROUP BY product_id
+	),
+	total AS (
+		SELECT COUNT(DISTINCT transaction_id) AS transactions FROM baskets
+	)`
+
+// ProductPairs counts the pairs of products bought in the same transaction
+// at least minTransactions times, most frequent first, then by lift. Support,
+// confidence and lift are rounded to 4 decimals.
+func (repo *reportRepository) ProductPairs(filter model.ReportFilter, minTransactions int, limit int) (*model.BasketAnalysis, error) {
+	analysis := model.BasketAnalysis{From: filter.From, To: filter.To, MinTransactions: minTransactions, Pairs: make([]model.ProductPair, 0)}
+	query := `WITH ` + basketsQuery + ` SELECT transactions FROM total`
+	err := repo.db.QueryRow(query, filter.From, filter.To, filter.LocationID).Scan(&analysis.TotalTransactions)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `WITH ` + basketsQuery + `,
+		pairs AS (
+			SELECT a.product_id AS product_a, b.product_id AS product_b, COUNT(*) AS transactions
+			FROM baskets a
+			JOIN baskets b ON b.transaction_id = a.transaction_id AND b.product_id > a.product_id
+			GROUP BY a.product_id, b.product_id
+			HAVING COUNT(*) >= $4
+		)
+		SELECT pa.id, pa.name, ia.transactions, pb.id, pb.name, ib.transactions, pairs.transactions,
+			ROUND(pairs.transactions::numeric / total.transactions, 4),
+			ROUND(pairs.transactions::numeric / ia.transactions, 4),
+			ROUND(pairs.transactions::numeric / ib.transactions, 4),
+			ROUND(pairs.transactions::numeric * total.transactions / (ia.transactions * ib.transactions), 4)
+		FROM pairs
+		JOIN items ia ON ia.product_id = pairs.product_a
+		JOIN items ib ON ib.product_id = pairs.product_b
+		JOIN products pa ON pa.id = pairs.product_a
+		JOIN products pb ON pb.id = pairs.product_b
+		CROSS JOIN total
+		ORDER BY pairs.transactions DESC,
+			pairs.transactions::numeric * total.transactions / (ia.transactions * ib.transactions) DESC,
+			pa.name, pb.name, pa.id, pb.id
+		LIMIT $5`
+	rows, err := repo.db.Query(query, filter.From, filter.To, filter.LocationID, minTransactions, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pair model.ProductPair
+		err := rows.Scan(&pair.ProductA.ID, &pair.ProductA.Name, &pair.ProductA.Transactions, &pair.ProductB.ID, &pair.ProductB.Name, &pair.ProductB.Transactions, &pair.Transactions, &pair.Support, &pair.ConfidenceAToB, &pair.ConfidenceBToA, &pair.Lift)
+		if err != nil {
+			return nil, err
+		}
+		analysis.Pairs = append(analysis.Pairs, pair)
+	}
+	return &analysis, rows.Err()
+}
+
+// RelatedProducts counts the products bought in the same transactions as
+// productID, most frequent first, with their current price. Deleted products
+// are left out.
+func (repo *reportRepository) RelatedProducts(productID int, filter model.ReportFilter, limit int) (*model.RelatedProducts, error) {
+	related := model.RelatedProducts{ProductID: productID, From: filter.From, To: filter.To, Related: make([]model.RelatedProduct, 0)}
+	query := `WITH ` + basketsQuery + ` SELECT COALESCE((SELECT transactions FROM items WHERE product_id = $4), 0)`
+	err := repo.db.QueryRow(query, filter.From, filter.To, filter.LocationID, productID).Scan(&related.Transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `WITH ` + basketsQuery + `,
+		together AS (
+			SELECT other.product_id, COUNT(*) AS transactions
+			FROM baskets base
+			JOIN baskets other ON other.transaction_id = base.transaction_id AND other.product_id <> base.product_id
+			WHERE base.product_id = $4
+			GROUP BY other.product_id
+		)
+		SELECT products.id, products.name, ` + effectivePrice + `, together.transactions,
+			ROUND(together.transactions::numeric / base.transactions, 4),
+			ROUND(together.transactions::numeric * total.transactions / (base.transactions * items.transactions), 4)
+		FROM together
+		JOIN items ON items.product_id = together.product_id
+		JOIN items base ON base.product_id = $4
+		JOIN products ON products.id = together.product_id
+		CROSS JOIN total
+		WHERE products.deleted_at IS NULL
+		ORDER BY together.transactions DESC,
+			together.transactions::numeric * total.transactions / items.transactions DESC,
+			products.name, products.id
+		LIMIT $5`
+	rows, err := repo.db.Query(query, filter.From, filter.To, filter.LocationID, productID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product model.RelatedProduct
+		err := rows.Scan(&product.ID, &product.Name, &product.Price, &product.Transactions, &product.Confidence, &product.Lift)
+		if err != nil {
+			return nil, err
+		}
+		related.Related = append(related.Related, product)
+	}
+	return &related, rows.Err()
+}
+
+// Inventory lists the stock on hand of every product at a location, or at all
+// locations when locationID is 0, with the quantity sold between the dates
+// salesFrom and salesTo and the date of the last sale. Stock held in batches
+// is valued at the batch cost; untracked stock at the average cost of all
+// batches ever received, and not at all for products never received.
+func (repo *reportRepository) Inventory(locationID int, salesFrom string, salesTo string) ([]model.InventoryRow, error) {
+	query := `WITH batches AS (
+			SELECT product_id, SUM(quantity) AS quantity, SUM(quantity::bigint * unit_cost) AS amount
+			FROM product_batches
+			WHERE $1 = 0 OR location_id = $1
+			GROUP BY product_id
+		),
+		received AS (
+			SELECT product_id, SUM(received_quantity::bigint * unit_cost)::numeric / NULLIF(SUM(received_quantity), 0) AS unit_cost
+			FROM product_batches
+			GROUP BY product_id
+		),
+		sales AS (
+			SELECT product_id,
+				COALESCE(SUM(quantity) FILTER (WHERE sale_date >= $2 AND sale_date < $3), 0) AS quantity,
+				MAX(sale_date) FILTER (WHERE quantity > 0) AS last_sold
+			FROM daily_sales
+			WHERE $1 = 0 OR location_id = $1
+			GROUP BY product_id
+		)
+		SELECT id, name, category_id, category_name, price, quantity,
+			tracked_quantity + CASE WHEN average_cost IS NULL THEN 0 ELSE GREATEST(quantity - tracked_quantity, 0) END,
+			(tracked_amount + COALESCE(ROUND(GREATEST(quantity - tracked_quantity, 0) * average_cost), 0))::bigint,
+			sold, COALESCE(TO_CHAR(last_sold, 'YYYY-MM-DD'), '')
+		FROM (
+			SELECT products.id, products.name, COALESCE(c.id, 0) AS category_id, COALESCE(c.name, '') AS category_name,
+				` + effectivePrice + ` AS price,
+				CASE WHEN $1 = 0 THEN products.stock ELSE COALESCE(sl.quantity, 0) END AS quantity,
+				COALESCE(b.quantity, 0) AS tracked_quantity, COALESCE(b.amount, 0) AS tracked_amount,
+				r.unit_cost AS average_cost, COALESCE(s.quantity, 0) AS sold, s.last_sold
+			FROM products
+			LEFT JOIN categories c ON c.id = products.category_id
+			LEFT JOIN stock_levels sl ON sl.product_id = products.id AND sl.location_id = $1
+			LEFT JOIN batches b ON b.product_id = products.id
+			LEFT JOIN received r ON r.product_id = products.id
+			LEFT JOIN sales s ON s.product_id = products.id
+			WHERE products.deleted_at IS NULL
+		) inventory
+		ORDER BY name, id`
+	rows, err := repo.db.Query(query, locationID, salesFrom, salesTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.InventoryRow, 0)
+	for rows.Next() {
+		var row model.InventoryRow
+		err := rows.Scan(&row.ID, &row.Name, &row.CategoryID, &row.CategoryName, &row.Price, &row.Quantity, &row.CostQuantity, &row.CostAmount, &row.SoldQuantity, &row.LastSoldDate)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+	"time"
+)
+
+var (
+	ErrReservationNotFound = errors.New("reservasi tidak ditemukan atau sudah kedaluwarsa")
+	ErrReservationExists   = errors.New("reservasi dengan referensi ini sudah ada")
+)
+
+type ReservationRepositoryInterface interface {
+	BeginTrans() (*sql.Tx, error)
+	CommitTrans(tx *sql.Tx) error
+	RollbackTrans(tx *sql.Tx) error
+	Reserve(tx *sql.Tx, cartID int, productID int, quantity int, expiresAt time.Time) error
+	Release(tx *sql.Tx, cartID int, productID int) error
+	ReleaseCart(tx *sql.Tx, cartID int) error
+	Extend(tx *sql.Tx, cartID int, expiresAt time.Time) error
+	GetReserved(tx *sql.Tx, productID int, excludeCartID int, excludeReference string) (int, error)
+	CreateOrder(tx *sql.Tx, reference string, productID int, quantity int, expiresAt time.Time) error
+	GetByReference(reference string) (*model.Reservation, error)
+	LockByReference(tx *sql.Tx, reference string) (*model.Reservation, error)
+	ReleaseReference(tx *sql.Tx, reference string) error
+	DeleteExpired() (int64, error)
+}
+
+type reservationRepository struct {
+	db *sql.DB
+}
+
+func NewReservationRepository(db *sql.DB) ReservationRepositoryInterface {
+	return &reservationRepository{db: db}
+}
+
+func (repo *reservationRepository) BeginTrans() (*sql.Tx, error) {
+	return repo.db.Begin()
+}
+
+func (repo *reservationRepository) CommitTrans(tx *sql.Tx) error {
+	return tx.Commit()
+}
+
+func (repo *reservationRepository) RollbackTrans(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+func (repo *reservationRepository) Reserve(tx *sql.Tx, cartID int, productID int, quantity int, expiresAt time.Time) error {
+	query := `INSERT INTO stock_reservations (cart_id, product_id, quantity, expires_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (cart_id, product_id) DO UPDATE SET quantity = EXCLUDED.quantity, expires_at = EXCLUDED.expires_at`
+	_, err := tx.Exec(query, cartID, productID, quantity, expiresAt)
+	return err
+}
+
+func (repo *reservationRepository) Release(tx *sql.Tx, cartID int, productID int) error {
+	_, err := tx.Exec("DELETE FROM stock_reservations WHERE cart_id = $1 AND product_id = $2", cartID, productID)
+	return err
+}
+
+func (repo *reservationRepository) ReleaseCart(tx *sql.Tx, cartID int) error {
+	_, err := tx.Exec("DELETE FROM stock_reservations WHERE cart_id = $1", cartID)
+	return err
+}
+
+// Extend pushes back the expiry of every reservation held by the cart.
+func (repo *reservationRepository) Extend(tx *sql.Tx, cartID int, expiresAt time.Time) error {
+	_, err := tx.Exec("UPDATE stock_reservations SET expires_at = $1 WHERE cart_id = $2", expiresAt, cartID)
+	return err
+}
+
+// GetReserved sums the unexpired reservations on a product, leaving out the
+// ones held by excludeCartID or excludeReference (0 and "" exclude nothing).
+func (repo *reservationRepository) GetReserved(tx *sql.Tx, productID int, excludeCartID int, excludeReference string) (int, error) {
+	query := `SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+		WHERE product_id = $1 AND expires_at > CURRENT_TIMESTAMP
+		AND ($2 = 0 OR cart_id IS DISTINCT FROM $2) AND ($3 = '' OR reference IS DISTINCT FROM $3)`
+	var reserved int
+	err := tx.QueryRow(query, productID, excludeCartID, excludeReference).Scan(&reserved)
+	return reserved, err
+}
+
+func (repo *reservationRepository) CreateOrder(tx *sql.Tx, reference string, productID int, quantity int, expiresAt time.Time) error {
+	// An expired reservation may still be waiting for the sweeper
+	_, err := tx.Exec("DELETE FROM stock_reservations WHERE reference = $1 AND product_id = $2 AND expires_at <= CURRENT_TIMESTAMP", reference, productID)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO stock_reservations (reference, product_id, quantity, expires_at) VALUES ($1, $2, $3, $4)"
+	_, err = tx.Exec(query, reference, productID, quantity, expiresAt)
+	if isUniqueViolation(err) {
+		return ErrReservationExists
+	}
+	return err
+}
+
+func (repo *reservationRepository) GetByReference(reference string) (*model.Reservation, error) {
+	query := `SELECT sr.product_id, p.name, sr.quantity, sr.expires_at, sr.created_at FROM stock_reservations sr
+		JOIN products p ON p.id = sr.product_id
+		WHERE sr.reference = $1 AND sr.expires_at > CURRENT_TIMESTAMP ORDER BY sr.id`
+	rows, err := repo.db.Query(query, reference)
+	if err != nil {
+		return nil, err
+	}
+	return scanReservation(reference, rows)
+}
+
+// LockByReference locks the unexpired rows of an order reservation so it can
+// only be consumed or released once.
+func (repo *reservationRepository) LockByReference(tx *sql.Tx, reference string) (*model.Reservation, error) {
+	query := `SELECT sr.product_id, p.name, sr.quantity, sr.expires_at, sr.created_at FROM stock_reservations sr
+		JOIN products p ON p.id = sr.product_id
+		WHERE sr.reference = $1 AND sr.expires_at > CURRENT_TIMESTAMP ORDER BY sr.id FOR UPDATE OF sr`
+	rows, err := tx.Query(query, reference)
+	if err != nil {
+		return nil, err
+	}
+	return scanReservation(reference, rows)
+}
+
+func (repo *reservationRepository) ReleaseReference(tx *sql.Tx, reference string) error {
+	result, err := tx.Exec("DELETE FROM stock_reservations WHERE reference = $1 AND expires_at > CURRENT_TIMESTAMP", reference)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrReservationNotFound
+	}
+	return nil
+}
+
+// DeleteExpired removes reservations whose time has passed. They no longer
+// count against stock anyway; this only keeps the table small.
+func (repo *reservationRepository) DeleteExpired() (int64, error) {
+	result, err := repo.db.Exec("DELETE FROM stock_reservations WHERE expires_at <= CURRENT_TIMESTAMP")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanReservation(reference string, rows *sql.Rows) (*model.Reservation, error) {
+	defer rows.Close()
+
+	reservation := model.Reservation{Reference: reference, Items: make([]model.ReservationItem, 0)}
+	for rows.Next() {
+		var item model.ReservationItem
+		err := rows.Scan(&item.ProductID, &item.ProductName, &item.Quantity, &reservation.ExpiresAt, &reservation.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		reservation.Items = append(reservation.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(reservation.Items) == 0 {
+		return nil, ErrReservationNotFound
+	}
+	return &reservation, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type RollupRepositoryInterface interface {
+	Add(tx *sql.Tx, transactionID int) error
+	Refund(tx *sql.Tx, transactionID int) error
+	Rebuild() error
+}
+
+// dailySalesInsert adds the lines of the selected transactions, times the
+// sign in $2, to the rollups of the day of the given timestamp column in the
+// timezone in $1. The discount and tax of a transaction are spread over its
+// lines the same way as in SalesByProduct.
+const dailySalesInsert = `INSERT INTO daily_sales (sale_date, location_id, product_id, quantity, gross_sales, discount_amount, tax_amount, cost_quantity, cost_amount)
+	SELECT (t.%s AT TIME ZONE $1)::date, COALESCE(t.location_id, 0), td.product_id,
+		$2::int * SUM(td.quantity), $2::int * SUM(td.subtotal),
+		$2::int * COALESCE(SUM(td.subtotal::numeric * t.discount_amount / NULLIF(t.total_amount + t.discount_amount, 0)), 0),
+		$2::int * COALESCE(SUM(td.subtotal::numeric * t.tax_amount / NULLIF(t.total_amount + t.discount_amount, 0)), 0),
+		$2::int * SUM(COALESCE(cost.quantity, 0)), $2::int * SUM(COALESCE(cost.amount, 0))
+	FROM transaction_details td
+	JOIN transactions t ON t.id = td.transaction_id
+	LEFT JOIN (
+		SELECT db.transaction_detail_id, SUM(db.quantity) AS quantity, SUM(db.quantity * b.unit_cost) AS amount
+		FROM transaction_detail_batches db JOIN product_batches b ON b.id = db.batch_id
+		GROUP BY db.transaction_detail_id
+	) cost ON cost.transaction_detail_id = td.id
+	WHERE %s
+	GROUP BY 1, 2, 3
+	ON CONFLICT (sale_date, location_id, product_id) DO UPDATE SET
+		quantity = daily_sales.quantity + EXCLUDED.quantity,
+		gross_sales = daily_sales.gross_sales + EXCLUDED.gross_sales,
+		discount_amount = daily_sales.discount_amount + EXCLUDED.discount_amount,
+		tax_amount = daily_sales.tax_amount + EXCLUDED.tax_amount,
+		cost_quantity = daily_sales.cost_quantity + EXCLUDED.cost_quantity,
+		cost_amount = daily_sales.cost_amount + EXCLUDED.cost_amount`
+
+const dailyTransactionsInsert = `INSERT INTO daily_transactions (sale_date, location_id, transaction_count, revenue, items_sold)
+	SELECT (t.%s AT TIME ZONE $1)::date, COALESCE(t.location_id, 0),
+		$2::int * COUNT(*), $2::int * SUM(t.total_amount), $2::int * COALESCE(SUM(d.items_sold), 0)
+	FROM transactions t
+	LEFT JOIN (
+		SELECT transaction_id, SUM(quantity) AS items_sold FROM transaction_details GROUP BY transaction_id
+	) d ON d.transaction_id = t.id
+	WHERE %s
+	GROUP BY 1, 2
+	ON CONFLICT (sale_date, location_id) DO UPDATE SET
+		transaction_count = daily_transactions.transaction_count + EXCLUDED.transaction_count,
+		revenue = daily_transactions.revenue + EXCLUDED.revenue,
+		items_sold = daily_transactions.items_sold + EXCLUDED.items_sold`
+
+type rollupRepository struct {
+	db       *sql.DB
+	timezone *time.Location
+}
+
+// NewRollupRepository creates the repository of the daily sales rollups. Days
+// are cut in timezone, which must stay the same for the rollups to add up;
+// rebuild them after changing it.
+func NewRollupRepository(db *sql.DB, timezone *time.Location) RollupRepositoryInterface {
+	return &rollupRepository{db: db, timezone: timezone}
+}
+
+// Add books a new transaction into the rollups as part of its checkout.
+func (repo *rollupRepository) Add(tx *sql.Tx, transactionID int) error {
+	return repo.apply(tx, "created_at", "t.id = $3", 1, transactionID)
+}
+
+// Refund books a refunded transaction as a negative entry on the day it was
+// refunded on, so days that may already be closed keep their totals. The
+// transaction must already be marked as refunded.
+func (repo *rollupRepository) Refund(tx *sql.Tx, transactionID int) error {
+	return repo.apply(tx, "refunded_at", "t.id = $3", -1, transactionID)
+}
+
+// Rebuild recomputes all rollups from the transactions, e.g. to backfill
+// history or after changing the store timezone.
+func (repo *rollupRepository) Rebuild() error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, query := range []string{"DELETE FROM daily_sales", "DELETE FROM daily_transactions"} {
+		_, err = tx.Exec(query)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	err = repo.apply(tx, "created_at", "TRUE", 1)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = repo.apply(tx, "refunded_at", "t.refunded_at IS NOT NULL", -1)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// apply books the transactions matching where, times sign, on the day of
+// their dateColumn.
+func (repo *rollupRepository) apply(tx *sql.Tx, dateColumn string, where string, sign int, args ...interface{}) error {
+	args = append([]interface{}{repo.timezone.String(), sign}, args...)
+	for _, insert := range []string{dailySalesInsert, dailyTransactionsInsert} {
+		_, err := tx.Exec(fmt.Sprintf(insert, dateColumn, where), args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+)
+
+var (
+	ErrShiftNotFound    = errors.New("shift tidak ditemukan")
+	ErrShiftAlreadyOpen = errors.New("kasir masih memiliki shift yang terbuka")
+	ErrShiftClosed      = errors.New("shift sudah ditutup")
+	ErrNoOpenShift      = errors.New("tidak ada shift terbuka untuk kasir ini")
+)
+
+const shiftColumns = "id, cashier, location_id, opening_float, opened_at, closed_at, expected_cash, counted_cash, note"
+
+type ShiftRepositoryInterface interface {
+	BeginTrans() (*sql.Tx, error)
+	CommitTrans(tx *sql.Tx) error
+	RollbackTrans(tx *sql.Tx) error
+	Open(shift *model.Shift) error
+	GetAll(cashier string, open *bool) ([]model.Shift, error)
+	GetByID(id int) (*model.Shift, error)
+	GetOpenByCashier(tx *sql.Tx, cashier string) (*model.Shift, error)
+	LockOpen(tx *sql.Tx, id int) (*model.Shift, error)
+	Close(tx *sql.Tx, shift *model.Shift) error
+	AddCashEvent(event *model.CashEvent) error
+	GetCashEvents(shiftID int) ([]model.CashEvent, error)
+	GetSales(shiftID int) (model.ShiftSales, error)
+	GetRefunds(shiftID int) (model.ShiftSales, error)
+}
+
+type shiftRepository struct {
+	db *sql.DB
+}
+
+func NewShiftRepository(db *sql.DB) ShiftRepositoryInterface {
+	return &shiftRepository{db: db}
+}
+
+func (repo *shiftRepository) BeginTrans() (*sql.Tx, error) {
+	return repo.db.Begin()
+}
+
+func (repo *shiftRepository) CommitTrans(tx *sql.Tx) error {
+	return tx.Commit()
+}
+
+func (repo *shiftRepository) RollbackTrans(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+func (repo *shiftRepository) Open(shift *model.Shift) error {
+	query := "INSERT INTO shifts (cashier, location_id, opening_float, note) VALUES ($1, $2, $3, $4) RETURNING id, opened_at"
+	err := repo.db.QueryRow(query, shift.Cashier, shift.LocationID, shift.OpeningFloat, shift.Note).Scan(&shift.ID, &shift.OpenedAt)
+	if isUniqueViolation(err) {
+		return ErrShiftAlreadyOpen
+	}
+	return err
+}
+
+func (repo *shiftRepository) GetAll(cashier string, open *bool) ([]model.Shift, error) {
+	query := "SELECT " + shiftColumns + " FROM shifts WHERE 1 = 1"
+	args := []interface{}{}
+	if cashier != "" {
+		args = append(args, cashier)
+		query += " AND cashier = $1"
+	}
+	if open != nil && *open {
+		query += " AND closed_at IS NULL"
+	}
+	if open != nil && !*open {
+		query += " AND closed_at IS NOT NULL"
+	}
+	query += " ORDER BY opened_at DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := make([]model.Shift, 0)
+	for rows.Next() {
+		var s model.Shift
+		err := rows.Scan(&s.ID, &s.Cashier, &s.LocationID, &s.OpeningFloat, &s.OpenedAt, &s.ClosedAt, &s.ExpectedCash, &s.CountedCash, &s.Note)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, s)
+	}
+	return shifts, rows.Err()
+}
+
+func (repo *shiftRepository) GetByID(id int) (*model.Shift, error) {
+	query := "SELECT " + shiftColumns + " FROM shifts WHERE id = $1"
+	return scanShift(repo.db.QueryRow(query, id), ErrShiftNotFound)
+}
+
+// GetOpenByCashier takes a share lock on the open shift so it cannot be
+// closed while a checkout is being written into it.
+func (repo *shiftRepository) GetOpenByCashier(tx *sql.Tx, cashier string) (*model.Shift, error) {
+	query := "SELECT " + shiftColumns + " FROM shifts WHERE cashier = $1 AND closed_at IS NULL FOR SHARE"
+	return scanShift(tx.QueryRow(query, cashier), ErrNoOpenShift)
+}
+
+// LockOpen locks an open shift for closing; it waits for in-flight checkouts.
+func (repo *shiftRepository) LockOpen(tx *sql.Tx, id int) (*model.Shift, error) {
+	query := "SELECT " + shiftColumns + " FROM shifts WHERE id = $1 FOR UPDATE"
+	shift, err := scanShift(tx.QueryRow(query, id), ErrShiftNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if shift.ClosedAt != nil {
+		return nil, ErrShiftClosed
+	}
+	return shift, nil
+}
+
+func (repo *shiftRepository) Close(tx *sql.Tx, shift *model.Shift) error {
+	query := "UPDATE shifts SET closed_at = CURRENT_TIMESTAMP, expected_cash = $1, counted_cash = $2, note = $3 WHERE id = $4 AND closed_at IS NULL RETURNING closed_at"
+	err := tx.QueryRow(query, shift.ExpectedCash, shift.CountedCash, shift.Note, shift.ID).Scan(&shift.ClosedAt)
+	if err == sql.ErrNoRows {
+		return ErrShiftClosed
+	}
+	return err
+}
+
+func (repo *shiftRepository) AddCashEvent(event *model.CashEvent) error {
+	query := `INSERT INTO shift_cash_events (shift_id, type, amount, note)
+		SELECT id, $2, $3, $4 FROM shifts WHERE id = $1 AND closed_at IS NULL
+		RETURNING id, created_at`
+	err := repo.db.QueryRow(query, event.ShiftID, event.Type, event.Amount, event.Note).Scan(&event.ID, &event.CreatedAt)
+	if err == sql.ErrNoRows {
+		return ErrShiftClosed
+	}
+	return err
+}
+
+func (repo *shiftRepository) GetCashEvents(shiftID int) ([]model.CashEvent, error) {
+	query := "SELECT id, shift_id, type, amount, note, created_at FROM shift_cash_events WHERE shift_id = $1 ORDER BY created_at, id"
+	rows, err := repo.db.Query(query, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]model.CashEvent, 0)
+	for rows.Next() {
+		var e model.CashEvent
+		err := rows.Scan(&e.ID, &e.ShiftID, &e.Type, &e.Amount, &e.Note, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// GetSales sums the checkouts of a shift per payment method. Refunded sales
+// stay in the shift they were made in; the refund is booked into the shift
+// that paid it out, see GetRefunds.
+func (repo *shiftRepository) GetSales(shiftID int) (model.ShiftSales, error) {
+	return repo.paymentTotals("shift_id", shiftID)
+}
+
+// GetRefunds sums the transactions refunded during a shift per payment
+// method of the original sale.
+func (repo *shiftRepository) GetRefunds(shiftID int) (model.ShiftSales, error) {
+	return repo.paymentTotals("refund_shift_id", shiftID)
+}
+
+// paymentTotals sums the transactions whose shift column is shiftID.
+func (repo *shiftRepository) paymentTotals(column string, shiftID int) (model.ShiftSales, error) {
+	sales := model.ShiftSales{Payments: make([]model.PaymentTotal, 0)}
+	query := "SELECT COUNT(*), COALESCE(SUM(total_amount), 0), COALESCE(SUM(change_amount), 0) FROM transactions WHERE " + column + " = $1"
+	err := repo.db.QueryRow(query, shiftID).Scan(&sales.TotalTransaction, &sales.TotalSales, &sales.ChangeGiven)
+	if err != nil {
+		return sales, err
+	}
+
+	paymentQuery := `SELECT tp.method, SUM(tp.amount) FROM transaction_payments tp
+		JOIN transactions t ON t.id = tp.transaction_id
+		WHERE t.` + column + ` = $1
+		GROUP BY tp.method ORDER BY tp.method`
+	rows, err := repo.db.Query(paymentQuery, shiftID)
+	if err != nil {
+		return sales, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p model.PaymentTotal
+		err := rows.Scan(&p.Method, &p.Amount)
+		if err != nil {
+			return sales, err
+		}
+		sales.Payments = append(sales.Payments, p)
+	}
+	return sales, rows.Err()
+}
+
+func scanShift(row *sql.Row, notFound error) (*model.Shift, error) {
+	var s model.Shift
+	err := row.Scan(&s.ID, &s.Cashier, &s.LocationID, &s.OpeningFloat, &s.OpenedAt, &s.ClosedAt, &s.ExpectedCash, &s.CountedCash, &s.Note)
+	if err == sql.ErrNoRows {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
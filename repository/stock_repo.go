@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+)
+
+var ErrLocationStockNotEnough = errors.New("stok produk di lokasi ini tidak cukup")
+
+type StockRepositoryInterface interface {
+	Adjust(tx *sql.Tx, locationID int, productID int, delta int) error
+	LockLevel(tx *sql.Tx, locationID int, productID int) (int, error)
+	TrimBatches(tx *sql.Tx, locationID int, productID int) error
+	GetByLocation(locationID int) ([]model.StockLevel, error)
+	GetByProduct(productID int) ([]model.StockLevel, error)
+	GetInTransit(productID int) (int, error)
+}
+
+type stockRepository struct {
+	db *sql.DB
+}
+
+func NewStockRepository(db *sql.DB) StockRepositoryInterface {
+	return &stockRepository{db: db}
+}
+
+// Adjust changes the stock of a product at a location and keeps the total in
+// products.stock in step. The level may not drop below zero; the caller is
+// expected to roll back tx on error.
+func (repo *stockRepository) Adjust(tx *sql.Tx, locationID int, productID int, delta int) error {
+	query := `INSERT INTO stock_levels (location_id, product_id, quantity) VALUES ($1, $2, $3)
+		ON CONFLICT (location_id, product_id) DO UPDATE SET quantity = stock_levels.quantity + EXCLUDED.quantity, updated_at = CURRENT_TIMESTAMP
+		RETURNING quantity`
+	var quantity int
+	err := tx.QueryRow(query, locationID, productID, delta).Scan(&quantity)
+	if err != nil {
+		return err
+	}
+	if quantity < 0 {
+		return ErrLocationStockNotEnough
+	}
+
+	result, err := tx.Exec("UPDATE products SET stock = stock + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", delta, productID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// LockLevel returns the current stock of a product at a location, locking
+// the level row if it exists.
+func (repo *stockRepository) LockLevel(tx *sql.Tx, locationID int, productID int) (int, error) {
+	var quantity int
+	err := tx.QueryRow("SELECT quantity FROM stock_levels WHERE location_id = $1 AND product_id = $2 FOR UPDATE", locationID, productID).Scan(&quantity)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return quantity, err
+}
+
+// TrimBatches takes stock written off outside of a sale or transfer, such as
+// a stocktake shortage, out of the batches so they never hold more than the
+// stock level. Stock not covered by batches is written off first, then the
+// batches earliest expiry first, expired ones included.
+func (repo *stockRepository) TrimBatches(tx *sql.Tx, locationID int, productID int) error {
+	level, err := repo.LockLevel(tx, locationID, productID)
+	if err != nil {
+		return err
+	}
+	query := `SELECT id, quantity FROM product_batches
+		WHERE product_id = $1 AND location_id = $2 AND quantity > 0
+		ORDER BY expiry_date NULLS LAST, id FOR UPDATE`
+	rows, err := tx.Query(query, productID, locationID)
+	if err != nil {
+		return err
+	}
+	var ids, quantities []int
+	tracked := 0
+	for rows.Next() {
+		var id, quantity int
+		err := rows.Scan(&id, &quantity)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+		quantities = append(quantities, quantity)
+		tracked += quantity
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	excess := tracked - level
+	for i := 0; i < len(ids) && excess > 0; i++ {
+		take := min(quantities[i], excess)
+		_, err = tx.Exec("UPDATE product_batches SET quantity = quantity - $1 WHERE id = $2", take, ids[i])
+		if err != nil {
+			return err
+		}
+		excess -= take
+	}
+	return nil
+}
+
+func (repo *stockRepository) GetByLocation(locationID int) ([]model.StockLevel, error) {
+	query := `SELECT sl.location_id, l.code, sl.product_id, p.name, sl.quantity FROM stock_levels sl
+		JOIN locations l ON l.id = sl.location_id
+		JOIN products p ON p.id = sl.product_id
+		WHERE sl.location_id = $1 AND p.deleted_at IS NULL ORDER BY p.name`
+	return repo.query(query, locationID)
+}
+
+func (repo *stockRepository) GetByProduct(productID int) ([]model.StockLevel, error) {
+	query := `SELECT sl.location_id, l.code, sl.product_id, p.name, sl.quantity FROM stock_levels sl
+		JOIN locations l ON l.id = sl.location_id
+		JOIN products p ON p.id = sl.product_id
+		WHERE sl.product_id = $1 ORDER BY l.code`
+	return repo.query(query, productID)
+}
+
+// GetInTransit sums the quantity of a product on transfers not yet received.
+func (repo *stockRepository) GetInTransit(productID int) (int, error) {
+	query := `SELECT COALESCE(SUM(ti.quantity), 0) FROM stock_transfer_items ti
+		JOIN stock_transfers t ON t.id = ti.transfer_id
+		WHERE ti.product_id = $1 AND t.status = 'in_transit'`
+	var quantity int
+	err := repo.db.QueryRow(query, productID).Scan(&quantity)
+	return quantity, err
+}
+
+func (repo *stockRepository) query(query string, args ...interface{}) ([]model.StockLevel, error) {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := make([]model.StockLevel, 0)
+	for rows.Next() {
+		var level model.StockLevel
+		err := rows.Scan(&level.LocationID, &level.LocationCode, &level.ProductID, &level.ProductName, &level.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, level)
+	}
+	return levels, rows.Err()
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+	"strconv"
+)
+
+var (
+	ErrStocktakeNotFound    = errors.New("stock opname tidak ditemukan")
+	ErrStocktakeInProgress  = errors.New("masih ada stock opname yang berjalan di lokasi ini")
+	ErrStocktakeNotCounting = errors.New("stock opname sudah disetujui atau dibatalkan")
+)
+
+const stocktakeColumns = "id, location_id, status, note, started_at, completed_at"
+
+type StocktakeRepositoryInterface interface {
+	BeginTrans() (*sql.Tx, error)
+	CommitTrans(tx *sql.Tx) error
+	RollbackTrans(tx *sql.Tx) error
+	Start(tx *sql.Tx, stocktake *model.Stocktake) error
+	GetAll(status string, locationID int) ([]model.Stocktake, error)
+	GetByID(id int) (*model.Stocktake, error)
+	LockCounting(tx *sql.Tx, id int) (*model.Stocktake, error)
+	GetItems(stocktakeID int) ([]model.StocktakeItem, error)
+	AddCount(tx *sql.Tx, stocktakeID int, productID int, quantity int, systemQuantity int, device string) error
+	SetAdjustment(tx *sql.Tx, stocktakeID int, item model.StocktakeItem) error
+	Complete(tx *sql.Tx, stocktake *model.Stocktake) error
+}
+
+type stocktakeRepository struct {
+	db *sql.DB
+}
+
+func NewStocktakeRepository(db *sql.DB) StocktakeRepositoryInterface {
+	return &stocktakeRepository{db: db}
+}
+
+func (repo *stocktakeRepository) BeginTrans() (*sql.Tx, error) {
+	return repo.db.Begin()
+}
+
+func (repo *stocktakeRepository) CommitTrans(tx *sql.Tx) error {
+	return tx.Commit()
+}
+
+func (repo *stocktakeRepository) RollbackTrans(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+// Start creates the stocktake and snapshots the expected stock of every
+// active product at the location.
+func (repo *stocktakeRepository) Start(tx *sql.Tx, stocktake *model.Stocktake) error {
+	query := "INSERT INTO stocktakes (location_id, status, note) VALUES ($1, $2, $3) RETURNING id, started_at"
+	err := tx.QueryRow(query, stocktake.LocationID, stocktake.Status, stocktake.Note).Scan(&stocktake.ID, &stocktake.StartedAt)
+	if isUniqueViolation(err) {
+		return ErrStocktakeInProgress
+	}
+	if err != nil {
+		return err
+	}
+
+	snapshotQuery := `INSERT INTO stocktake_items (stocktake_id, product_id, expected)
+		SELECT $1, p.id, COALESCE(sl.quantity, 0) FROM products p
+		LEFT JOIN stock_levels sl ON sl.product_id = p.id AND sl.location_id = $2
+		WHERE p.deleted_at IS NULL`
+	_, err = tx.Exec(snapshotQuery, stocktake.ID, stocktake.LocationID)
+	return err
+}
+
+func (repo *stocktakeRepository) GetAll(status string, locationID int) ([]model.Stocktake, error) {
+	query := "SELECT " + stocktakeColumns + " FROM stocktakes WHERE 1 = 1"
+	args := []interface{}{}
+	if status != "" {
+		args = append(args, status)
+		query += " AND status = $" + strconv.Itoa(len(args))
+	}
+	if locationID != 0 {
+		args = append(args, locationID)
+		query += " AND location_id = $" + strconv.Itoa(len(args))
+	}
+	query += " ORDER BY started_at DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stocktakes := make([]model.Stocktake, 0)
+	for rows.Next() {
+		var s model.Stocktake
+		err := rows.Scan(&s.ID, &s.LocationID, &s.Status, &s.Note, &s.StartedAt, &s.CompletedAt)
+		if err != nil {
+			return nil, err
+		}
+		stocktakes = append(stocktakes, s)
+	}
+	return stocktakes, rows.Err()
+}
+
+func (repo *stocktakeRepository) GetByID(id int) (*model.Stocktake, error) {
+	query := "SELECT " + stocktakeColumns + " FROM stocktakes WHERE id = $1"
+	return scanStocktake(repo.db.QueryRow(query, id))
+}
+
+// LockCounting locks a stocktake that is still counting. Counts and the
+// approval are serialized on this lock.
+func (repo *stocktakeRepository) LockCounting(tx *sql.Tx, id int) (*model.Stocktake, error) {
+	query := "SELECT " + stocktakeColumns + " FROM stocktakes WHERE id = $1 FOR UPDATE"
+	stocktake, err := scanStocktake(tx.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	if stocktake.Status != model.StocktakeStatusCounting {
+		return nil, ErrStocktakeNotCounting
+	}
+	return stocktake, nil
+}
+
+func (repo *stocktakeRepository) GetItems(stocktakeID int) ([]model.StocktakeItem, error) {
+	query := `SELECT si.product_id, products.name, ` + effectivePrice + `, si.expected, si.system_quantity, si.counted, si.counted_at, si.adjustment
+		FROM stocktake_items si JOIN products ON products.id = si.product_id
+		WHERE si.stocktake_id = $1 ORDER BY products.name, si.product_id`
+	rows, err := repo.db.Query(query, stocktakeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]model.StocktakeItem, 0)
+	for rows.Next() {
+		var item model.StocktakeItem
+		err := rows.Scan(&item.ProductID, &item.ProductName, &item.Price, &item.Expected, &item.SystemQuantity, &item.Counted, &item.CountedAt, &item.Adjustment)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// AddCount adds a (partial) count to the product and records the stock the
+// system held at that moment. Products created after the snapshot get a line
+// with an expected stock of zero.
+func (repo *stocktakeRepository) AddCount(tx *sql.Tx, stocktakeID int, productID int, quantity int, systemQuantity int, device string) error {
+	query := `INSERT INTO stocktake_items (stocktake_id, product_id, expected, system_quantity, counted, counted_at)
+		VALUES ($1, $2, 0, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (stocktake_id, product_id) DO UPDATE SET
+			counted = COALESCE(stocktake_items.counted, 0) + EXCLUDED.counted,
+			system_quantity = EXCLUDED.system_quantity,
+			counted_at = EXCLUDED.counted_at`
+	_, err := tx.Exec(query, stocktakeID, productID, systemQuantity, quantity)
+	if err != nil {
+		return err
+	}
+
+	countQuery := "INSERT INTO stocktake_counts (stocktake_id, product_id, quantity, device) VALUES ($1, $2, $3, $4)"
+	_, err = tx.Exec(countQuery, stocktakeID, productID, quantity, device)
+	return err
+}
+
+func (repo *stocktakeRepository) SetAdjustment(tx *sql.Tx, stocktakeID int, item model.StocktakeItem) error {
+	query := "UPDATE stocktake_items SET system_quantity = $1, counted = $2, adjustment = $3 WHERE stocktake_id = $4 AND product_id = $5"
+	_, err := tx.Exec(query, item.SystemQuantity, item.Counted, item.Adjustment, stocktakeID, item.ProductID)
+	return err
+}
+
+func (repo *stocktakeRepository) Complete(tx *sql.Tx, stocktake *model.Stocktake) error {
+	query := "UPDATE stocktakes SET status = $1, completed_at = CURRENT_TIMESTAMP WHERE id = $2 AND status = 'counting' RETURNING completed_at"
+	err := tx.QueryRow(query, stocktake.Status, stocktake.ID).Scan(&stocktake.CompletedAt)
+	if err == sql.ErrNoRows {
+		return ErrStocktakeNotCounting
+	}
+	return err
+}
+
+func scanStocktake(row *sql.Row) (*model.Stocktake, error) {
+	var s model.Stocktake
+	err := row.Scan(&s.ID, &s.LocationID, &s.Status, &s.Note, &s.StartedAt, &s.CompletedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrStocktakeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -2,12 +2,23 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"product-api/model"
+	"strconv"
+	"time"
 )
 
+var ErrTransactionNotFound = errors.New("transaksi tidak ditemukan")
+
+const transactionColumns = "id, COALESCE(invoice_number, ''), COALESCE(store_code, ''), total_amount, discount_amount, tax_amount, paid_amount, change_amount, customer_id, shift_id, location_id, created_at, refunded_at, refund_shift_id"
+
 type TransactionRepositoryInterface interface {
 	Create(tx *sql.Tx, transaction *model.Transaction) error
-	GetAll(fromDate string, toDate string) ([]model.Transaction, error)
+	GetAll(from time.Time, to time.Time, locationID int) ([]model.Transaction, error)
+	GetByCustomerID(customerID int) ([]model.Transaction, error)
+	GetByID(id int) (*model.Transaction, error)
+	GetByInvoiceNumber(storeCode string, invoiceNumber string) (*model.Transaction, error)
+	MarkRefunded(tx *sql.Tx, id int, refundShiftID *int) error
 }
 
 type transactionRepository struct {
@@ -18,77 +29,239 @@ func NewTransactionRepository(db *sql.DB) TransactionRepositoryInterface {
 	return &transactionRepository{db: db}
 }
 
-func (repo *transactionRepository) Create(tx *sql.Tx, transaction *model.Transaction) (error) {
-	query := "INSERT INTO transactions (total_amount) VALUES ($1) RETURNING id, created_at"
-	err := tx.QueryRow(query, transaction.TotalAmount).Scan(&transaction.ID, &transaction.CreatedAt)
+func (repo *transactionRepository) Create(tx *sql.Tx, transaction *model.Transaction) error {
+	query := "INSERT INTO transactions (invoice_number, store_code, total_amount, discount_amount, tax_amount, paid_amount, change_amount, customer_id, shift_id, location_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at"
+	err := tx.QueryRow(
+		query,
+		transaction.InvoiceNumber,
+		transaction.StoreCode,
+		transaction.TotalAmount,
+		transaction.DiscountAmount,
+		transaction.TaxAmount,
+		transaction.PaidAmount,
+		transaction.ChangeAmount,
+		transaction.CustomerID,
+		transaction.ShiftID,
+		transaction.LocationID,
+	).Scan(&transaction.ID, &transaction.CreatedAt)
 	if err != nil {
 		return err
 	}
 
-	detailQuery := "INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES ($1, $2, $3, $4) RETURNING id"
+	detailQuery := "INSERT INTO transaction_details (transaction_id, product_id, price, quantity, subtotal) VALUES ($1, $2, $3, $4, $5) RETURNING id"
 	for i := range transaction.Details {
 		transaction.Details[i].TransactionID = transaction.ID
 		err = tx.QueryRow(
 			detailQuery,
 			transaction.Details[i].TransactionID,
 			transaction.Details[i].ProductID,
+			transaction.Details[i].Price,
 			transaction.Details[i].Quantity,
 			transaction.Details[i].Subtotal,
 		).Scan(&transaction.Details[i].ID)
 		if err != nil {
 			return err
 		}
+		for _, allocation := range transaction.Details[i].Batches {
+			_, err = tx.Exec(
+				"INSERT INTO transaction_detail_batches (transaction_detail_id, batch_id, quantity) VALUES ($1, $2, $3)",
+				transaction.Details[i].ID,
+				allocation.BatchID,
+				allocation.Quantity,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	paymentQuery := "INSERT INTO transaction_payments (transaction_id, method, amount) VALUES ($1, $2, $3) RETURNING id"
+	for i := range transaction.Payments {
+		transaction.Payments[i].TransactionID = transaction.ID
+		err = tx.QueryRow(
+			paymentQuery,
+			transaction.Payments[i].TransactionID,
+			transaction.Payments[i].Method,
+			transaction.Payments[i].Amount,
+		).Scan(&transaction.Payments[i].ID)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (repo *transactionRepository) GetAll(fromDate string, toDate string) ([]model.Transaction, error) {
-	query := "SELECT id, total_amount, created_at FROM transactions"
-	var rows *sql.Rows
-	var err error
+func (repo *transactionRepository) GetAll(from time.Time, to time.Time, locationID int) ([]model.Transaction, error) {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE 1 = 1"
+	args := []interface{}{}
+	if !from.IsZero() && !to.IsZero() {
+		args = append(args, from, to)
+		query += " AND created_at >= $1 AND created_at < $2"
+	}
+	if locationID != 0 {
+		args = append(args, locationID)
+		query += " AND location_id = $" + strconv.Itoa(len(args))
+	}
+	query += " ORDER BY created_at DESC"
 
-	if fromDate != "" && toDate != "" {
-		query += " WHERE created_at BETWEEN $1 AND $2 ORDER BY created_at DESC"
-		rows, err = repo.db.Query(query, fromDate, toDate)
-	} else {
-		query += " ORDER BY created_at DESC"
-		rows, err = repo.db.Query(query)
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
 	}
+	return repo.scanTransactions(rows)
+}
+
+func (repo *transactionRepository) GetByCustomerID(customerID int) ([]model.Transaction, error) {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE customer_id = $1 ORDER BY created_at DESC"
+	rows, err := repo.db.Query(query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.scanTransactions(rows)
+}
+
+func (repo *transactionRepository) GetByID(id int) (*model.Transaction, error) {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE id = $1"
+	return repo.getOne(query, id)
+}
 
+func (repo *transactionRepository) GetByInvoiceNumber(storeCode string, invoiceNumber string) (*model.Transaction, error) {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE store_code = $1 AND invoice_number = $2"
+	return repo.getOne(query, storeCode, invoiceNumber)
+}
+
+func (repo *transactionRepository) getOne(query string, args ...interface{}) (*model.Transaction, error) {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := repo.scanTransactions(rows)
 	if err != nil {
 		return nil, err
 	}
+	if len(transactions) == 0 {
+		return nil, ErrTransactionNotFound
+	}
+	return &transactions[0], nil
+}
+
+func (repo *transactionRepository) MarkRefunded(tx *sql.Tx, id int, refundShiftID *int) error {
+	query := "UPDATE transactions SET refunded_at = CURRENT_TIMESTAMP, refund_shift_id = $2 WHERE id = $1 AND refunded_at IS NULL"
+	result, err := tx.Exec(query, id, refundShiftID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("transaksi sudah direfund")
+	}
+	return nil
+}
+
+func (repo *transactionRepository) scanTransactions(rows *sql.Rows) ([]model.Transaction, error) {
 	defer rows.Close()
 
 	transactions := make([]model.Transaction, 0)
 	for rows.Next() {
 		var transaction model.Transaction
-		err := rows.Scan(&transaction.ID, &transaction.TotalAmount, &transaction.CreatedAt)
+		err := rows.Scan(&transaction.ID, &transaction.InvoiceNumber, &transaction.StoreCode, &transaction.TotalAmount, &transaction.DiscountAmount, &transaction.TaxAmount, &transaction.PaidAmount, &transaction.ChangeAmount, &transaction.CustomerID, &transaction.ShiftID, &transaction.LocationID, &transaction.CreatedAt, &transaction.RefundedAt, &transaction.RefundShiftID)
 		if err != nil {
 			return nil, err
 		}
+		transactions = append(transactions, transaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-		detailQuery := "SELECT id, transaction_id, product_id, quantity, subtotal FROM transaction_details WHERE transaction_id = $1"
-		detailRows, err := repo.db.Query(detailQuery, transaction.ID)
+	for i := range transactions {
+		details, err := repo.getDetails(transactions[i].ID)
 		if err != nil {
 			return nil, err
 		}
+		transactions[i].Details = details
 
-		details := make([]model.TransactionDetail, 0)
-		for detailRows.Next() {
-			var detail model.TransactionDetail
-			err := detailRows.Scan(&detail.ID, &detail.TransactionID, &detail.ProductID, &detail.Quantity, &detail.Subtotal)
-			if err != nil {
-				detailRows.Close()
-				return nil, err
-			}
-			details = append(details, detail)
+		payments, err := repo.getPayments(transactions[i].ID)
+		if err != nil {
+			return nil, err
 		}
-		detailRows.Close()
-		transaction.Details = details
-		transactions = append(transactions, transaction)
+		transactions[i].Payments = payments
 	}
-
 	return transactions, nil
 }
+
+func (repo *transactionRepository) getDetails(transactionID int) ([]model.TransactionDetail, error) {
+	detailQuery := "SELECT td.id, td.transaction_id, td.product_id, p.name, COALESCE(td.price, 0), td.quantity, td.subtotal FROM transaction_details td JOIN products p ON p.id = td.product_id WHERE td.transaction_id = $1 ORDER BY td.id"
+	detailRows, err := repo.db.Query(detailQuery, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer detailRows.Close()
+
+	details := make([]model.TransactionDetail, 0)
+	for detailRows.Next() {
+		var detail model.TransactionDetail
+		err := detailRows.Scan(&detail.ID, &detail.TransactionID, &detail.ProductID, &detail.ProductName, &detail.Price, &detail.Quantity, &detail.Subtotal)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, detail)
+	}
+	if err := detailRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range details {
+		details[i].Batches, err = repo.getDetailBatches(details[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return details, nil
+}
+
+func (repo *transactionRepository) getDetailBatches(detailID int) ([]model.BatchAllocation, error) {
+	query := `SELECT db.batch_id, b.batch_number, TO_CHAR(b.expiry_date, 'YYYY-MM-DD'), db.quantity
+		FROM transaction_detail_batches db JOIN product_batches b ON b.id = db.batch_id
+		WHERE db.transaction_detail_id = $1 ORDER BY db.id`
+	rows, err := repo.db.Query(query, detailID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allocations []model.BatchAllocation
+	for rows.Next() {
+		var allocation model.BatchAllocation
+		err := rows.Scan(&allocation.BatchID, &allocation.BatchNumber, &allocation.ExpiryDate, &allocation.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, allocation)
+	}
+	return allocations, rows.Err()
+}
+
+func (repo *transactionRepository) getPayments(transactionID int) ([]model.Payment, error) {
+	query := "SELECT id, transaction_id, method, amount FROM transaction_payments WHERE transaction_id = $1 ORDER BY id"
+	rows, err := repo.db.Query(query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]model.Payment, 0)
+	for rows.Next() {
+		var payment model.Payment
+		err := rows.Scan(&payment.ID, &payment.TransactionID, &payment.Method, &payment.Amount)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+	return payments, rows.Err()
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+	"strconv"
+)
+
+var (
+	ErrTransferNotFound     = errors.New("transfer stok tidak ditemukan")
+	ErrTransferNotInTransit = errors.New("transfer stok sudah diterima atau dibatalkan")
+)
+
+const transferColumns = "id, from_location_id, to_location_id, status, note, created_at, completed_at"
+
+type TransferRepositoryInterface interface {
+	BeginTrans() (*sql.Tx, error)
+	CommitTrans(tx *sql.Tx) error
+	RollbackTrans(tx *sql.Tx) error
+	Create(tx *sql.Tx, transfer *model.StockTransfer) error
+	GetAll(status string, locationID int) ([]model.StockTransfer, error)
+	GetByID(id int) (*model.StockTransfer, error)
+	LockInTransit(tx *sql.Tx, id int) (*model.StockTransfer, error)
+	Complete(tx *sql.Tx, transfer *model.StockTransfer) error
+}
+
+type transferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) TransferRepositoryInterface {
+	return &transferRepository{db: db}
+}
+
+func (repo *transferRepository) BeginTrans() (*sql.Tx, error) {
+	return repo.db.Begin()
+}
+
+func (repo *transferRepository) CommitTrans(tx *sql.Tx) error {
+	return tx.Commit()
+}
+
+func (repo *transferRepository) RollbackTrans(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+func (repo *transferRepository) Create(tx *sql.Tx, transfer *model.StockTransfer) error {
+	query := "INSERT INTO stock_transfers (from_location_id, to_location_id, status, note) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
+	err := tx.QueryRow(query, transfer.FromLocationID, transfer.ToLocationID, transfer.Status, transfer.Note).Scan(&transfer.ID, &transfer.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	itemQuery := "INSERT INTO stock_transfer_items (transfer_id, product_id, quantity) VALUES ($1, $2, $3)"
+	for _, item := range transfer.Items {
+		_, err = tx.Exec(itemQuery, transfer.ID, item.ProductID, item.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *transferRepository) GetAll(status string, locationID int) ([]model.StockTransfer, error) {
+	query := "SELECT " + transferColumns + " FROM stock_transfers WHERE 1 = 1"
+	args := []interface{}{}
+	if status != "" {
+		args = append(args, status)
+		query += " AND status = $" + strconv.Itoa(len(args))
+	}
+	if locationID != 0 {
+		args = append(args, locationID)
+		query += " AND (from_location_id = $" + strconv.Itoa(len(args)) + " OR to_location_id = $" + strconv.Itoa(len(args)) + ")"
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := make([]model.StockTransfer, 0)
+	for rows.Next() {
+		var t model.StockTransfer
+		err := rows.Scan(&t.ID, &t.FromLocationID, &t.ToLocationID, &t.Status, &t.Note, &t.CreatedAt, &t.CompletedAt)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range transfers {
+		transfers[i].Items, err = repo.getItems(transfers[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return transfers, nil
+}
+
+func (repo *transferRepository) GetByID(id int) (*model.StockTransfer, error) {
+	query := "SELECT " + transferColumns + " FROM stock_transfers WHERE id = $1"
+	transfer, err := scanTransfer(repo.db.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	transfer.Items, err = repo.getItems(id)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// LockInTransit locks a transfer that is still in transit so it can be
+// received or cancelled exactly once.
+func (repo *transferRepository) LockInTransit(tx *sql.Tx, id int) (*model.StockTransfer, error) {
+	query := "SELECT " + transferColumns + " FROM stock_transfers WHERE id = $1 FOR UPDATE"
+	transfer, err := scanTransfer(tx.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != model.TransferStatusInTransit {
+		return nil, ErrTransferNotInTransit
+	}
+	transfer.Items, err = repo.getItems(id)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (repo *transferRepository) Complete(tx *sql.Tx, transfer *model.StockTransfer) error {
+	query := "UPDATE stock_transfers SET status = $1, completed_at = CURRENT_TIMESTAMP WHERE id = $2 AND status = 'in_transit' RETURNING completed_at"
+	err := tx.QueryRow(query, transfer.Status, transfer.ID).Scan(&transfer.CompletedAt)
+	if err == sql.ErrNoRows {
+		return ErrTransferNotInTransit
+	}
+	return err
+}
+
+func (repo *transferRepository) getItems(transferID int) ([]model.CheckoutItem, error) {
+	rows, err := repo.db.Query("SELECT product_id, quantity FROM stock_transfer_items WHERE transfer_id = $1 ORDER BY id", transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]model.CheckoutItem, 0)
+	for rows.Next() {
+		var item model.CheckoutItem
+		err := rows.Scan(&item.ProductID, &item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func scanTransfer(row *sql.Row) (*model.StockTransfer, error) {
+	var t model.StockTransfer
+	err := row.Scan(&t.ID, &t.FromLocationID, &t.ToLocationID, &t.Status, &t.Note, &t.CreatedAt, &t.CompletedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
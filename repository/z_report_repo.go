@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+)
+
+var (
+	ErrZReportNotFound = errors.New("Z-report tidak ditemukan")
+	ErrDayClosed       = errors.New("hari sudah ditutup dengan Z-report")
+)
+
+const zReportColumns = `id, number, location_id, TO_CHAR(business_date, 'YYYY-MM-DD'), timezone, period_start, period_end,
+	transaction_count, gross_sales, discount_amount, net_sales, tax_amount, change_given,
+	void_count, void_amount, refund_count, refund_amount, first_invoice, last_invoice, closed_by, note, closed_at`
+
+type ZReportRepositoryInterface interface {
+	BeginTrans() (*sql.Tx, error)
+	CommitTrans(tx *sql.Tx) error
+	RollbackTrans(tx *sql.Tx) error
+	LockLocation(tx *sql.Tx, locationID int) error
+	CheckOpen(tx *sql.Tx, locationID int, businessDate string) error
+	Compute(tx *sql.Tx, report *model.ZReport) error
+	Create(tx *sql.Tx, report *model.ZReport) error
+	GetAll(locationID int) ([]model.ZReport, error)
+	GetByID(id int) (*model.ZReport, error)
+}
+
+type zReportRepository struct {
+	db *sql.DB
+}
+
+func NewZReportRepository(db *sql.DB) ZReportRepositoryInterface {
+	return &zReportRepository{db: db}
+}
+
+func (repo *zReportRepository) BeginTrans() (*sql.Tx, error) {
+	return repo.db.Begin()
+}
+
+func (repo *zReportRepository) CommitTrans(tx *sql.Tx) error {
+	return tx.Commit()
+}
+
+func (repo *zReportRepository) RollbackTrans(tx *sql.Tx) error {
+	return tx.Rollback()
+}
+
+// LockLocation locks the location for closing a day; it waits for in-flight
+// checkouts, which hold a key share lock on it through CheckOpen.
+func (repo *zReportRepository) LockLocation(tx *sql.Tx, locationID int) error {
+	var id int
+	err := tx.QueryRow("SELECT id FROM locations WHERE id = $1 FOR UPDATE", locationID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrLocationNotFound
+	}
+	return err
+}
+
+// CheckOpen returns ErrDayClosed when the business day of the location has a
+// Z-report. It keeps the location from being closed until tx ends.
+func (repo *zReportRepository) CheckOpen(tx *sql.Tx, locationID int, businessDate string) error {
+	query := `SELECT EXISTS (SELECT 1 FROM z_reports WHERE location_id = l.id AND business_date = $2)
+		FROM locations l WHERE l.id = $1 FOR KEY SHARE OF l`
+	var closed bool
+	err := tx.QueryRow(query, locationID, businessDate).Scan(&closed)
+	if err == sql.ErrNoRows {
+		return ErrLocationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if closed {
+		return ErrDayClosed
+	}
+	return nil
+}
+
+// Compute fills in the totals of the report's location and period.
+func (repo *zReportRepository) Compute(tx *sql.Tx, report *model.ZReport) error {
+	salesQuery := `SELECT COUNT(*) FILTER (WHERE NOT voided),
+			COALESCE(SUM(total_amount + discount_amount) FILTER (WHERE NOT voided), 0),
+			COALESCE(SUM(discount_amount) FILTER (WHERE NOT voided), 0),
+			COALESCE(SUM(total_amount) FILTER (WHERE NOT voided), 0),
+			COALESCE(SUM(tax_amount) FILTER (WHERE NOT voided), 0),
+			COALESCE(SUM(change_amount) FILTER (WHERE NOT voided), 0),
+			COUNT(*) FILTER (WHERE voided),
+			COALESCE(SUM(total_amount) FILTER (WHERE voided), 0)
+		FROM (
+			SELECT t.*, COALESCE(t.refunded_at < $3, false) AS voided FROM transactions t
+			WHERE t.location_id = $1 AND t.created_at >= $2 AND t.created_at < $3
+		) day`
+	err := tx.QueryRow(salesQuery, report.LocationID, report.PeriodStart, report.PeriodEnd).Scan(
+		&report.TransactionCount, &report.GrossSales, &report.Discount, &report.NetSales, &report.Tax,
+		&report.ChangeGiven, &report.VoidCount, &report.VoidAmount,
+	)
+	if err != nil {
+		return err
+	}
+
+	refundQuery := `SELECT COUNT(*), COALESCE(SUM(total_amount), 0) FROM transactions
+		WHERE location_id = $1 AND refunded_at >= $2 AND refunded_at < $3 AND created_at < $2`
+	err = tx.QueryRow(refundQuery, report.LocationID, report.PeriodStart, report.PeriodEnd).Scan(&report.RefundCount, &report.RefundAmount)
+	if err != nil {
+		return err
+	}
+
+	invoiceQuery := `SELECT COALESCE(MIN(invoice_number) FILTER (WHERE is_first), ''), COALESCE(MIN(invoice_number) FILTER (WHERE is_last), '')
+		FROM (
+			SELECT invoice_number,
+				ROW_NUMBER() OVER (ORDER BY created_at, id) = 1 AS is_first,
+				ROW_NUMBER() OVER (ORDER BY created_at DESC, id DESC) = 1 AS is_last
+			FROM transactions
+			WHERE location_id = $1 AND created_at >= $2 AND created_at < $3 AND invoice_number IS NOT NULL
+		) invoices`
+	err = tx.QueryRow(invoiceQuery, report.LocationID, report.PeriodStart, report.PeriodEnd).Scan(&report.FirstInvoice, &report.LastInvoice)
+	if err != nil {
+		return err
+	}
+
+	paymentQuery := `SELECT tp.method, SUM(tp.amount) FROM transaction_payments tp
+		JOIN transactions t ON t.id = tp.transaction_id
+		WHERE t.location_id = $1 AND t.created_at >= $2 AND t.created_at < $3
+			AND (t.refunded_at IS NULL OR t.refunded_at >= $3)
+		GROUP BY tp.method ORDER BY tp.method`
+	rows, err := tx.Query(paymentQuery, report.LocationID, report.PeriodStart, report.PeriodEnd)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	report.Payments = make([]model.PaymentTotal, 0)
+	for rows.Next() {
+		var p model.PaymentTotal
+		err := rows.Scan(&p.Method, &p.Amount)
+		if err != nil {
+			return err
+		}
+		report.Payments = append(report.Payments, p)
+	}
+	return rows.Err()
+}
+
+// Create stores the report under the next number of its location. The
+// location must be locked with LockLocation.
+func (repo *zReportRepository) Create(tx *sql.Tx, report *model.ZReport) error {
+	err := tx.QueryRow("SELECT COALESCE(MAX(number), 0) + 1 FROM z_reports WHERE location_id = $1", report.LocationID).Scan(&report.Number)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO z_reports (location_id, number, business_date, timezone, period_start, period_end,
+			transaction_count, gross_sales, discount_amount, net_sales, tax_amount, change_given,
+			void_count, void_amount, refund_count, refund_amount, first_invoice, last_invoice, closed_by, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		RETURNING id, closed_at`
+	err = tx.QueryRow(
+		query,
+		report.LocationID, report.Number, report.BusinessDate, report.Timezone, report.PeriodStart, report.PeriodEnd,
+		report.TransactionCount, report.GrossSales, report.Discount, report.NetSales, report.Tax, report.ChangeGiven,
+		report.VoidCount, report.VoidAmount, report.RefundCount, report.RefundAmount,
+		report.FirstInvoice, report.LastInvoice, report.ClosedBy, report.Note,
+	).Scan(&report.ID, &report.ClosedAt)
+	if isUniqueViolation(err) {
+		return ErrDayClosed
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range report.Payments {
+		_, err = tx.Exec("INSERT INTO z_report_payments (z_report_id, method, amount) VALUES ($1, $2, $3)", report.ID, payment.Method, payment.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *zReportRepository) GetAll(locationID int) ([]model.ZReport, error) {
+	query := "SELECT " + zReportColumns + " FROM z_reports WHERE ($1 = 0 OR location_id = $1) ORDER BY business_date DESC, location_id"
+	rows, err := repo.db.Query(query, locationID)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := scanZReports(rows)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reports {
+		reports[i].Payments, err = repo.getPayments(reports[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return reports, nil
+}
+
+func (repo *zReportRepository) GetByID(id int) (*model.ZReport, error) {
+	query := "SELECT " + zReportColumns + " FROM z_reports WHERE id = $1"
+	rows, err := repo.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := scanZReports(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, ErrZReportNotFound
+	}
+	reports[0].Payments, err = repo.getPayments(id)
+	if err != nil {
+		return nil, err
+	}
+	return &reports[0], nil
+}
+
+func (repo *zReportRepository) getPayments(reportID int) ([]model.PaymentTotal, error) {
+	rows, err := repo.db.Query("SELECT method, amount FROM z_report_payments WHERE z_report_id = $1 ORDER BY method", reportID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]model.PaymentTotal, 0)
+	for rows.Next() {
+		var p model.PaymentTotal
+		err := rows.Scan(&p.Method, &p.Amount)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+func scanZReports(rows *sql.Rows) ([]model.ZReport, error) {
+	defer rows.Close()
+
+	reports := make([]model.ZReport, 0)
+	for rows.Next() {
+		var r model.ZReport
+		err := rows.Scan(
+			&r.ID, &r.Number, &r.LocationID, &r.BusinessDate, &r.Timezone, &r.PeriodStart, &r.PeriodEnd,
+			&r.TransactionCount, &r.GrossSales, &r.Discount, &r.NetSales, &r.Tax, &r.ChangeGiven,
+			&r.VoidCount, &r.VoidAmount, &r.RefundCount, &r.RefundAmount, &r.FirstInvoice, &r.LastInvoice,
+			&r.ClosedBy, &r.Note, &r.ClosedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
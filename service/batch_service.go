@@ -0,0 +1,205 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/utils/period"
+	"strings"
+	"time"
+)
+
+type BatchServiceInterface interface {
+	ReceiveGoods(receipt *model.GoodsReceipt) error
+	GetReceipts(locationID int) ([]model.GoodsReceipt, error)
+	GetReceiptByID(id int) (*model.GoodsReceipt, error)
+	GetBatches(productID int, locationID int, includeEmpty bool) ([]model.Batch, error)
+	GetExpiring(days int, locationID int) ([]model.ExpiringBatch, error)
+	Allocate(tx *sql.Tx, productID int, locationID int, quantity int) ([]model.BatchAllocation, error)
+	Release(tx *sql.Tx, allocations []model.BatchAllocation) error
+}
+
+type batchService struct {
+	batchRepo       repository.BatchRepositoryInterface
+	stockRepo       repository.StockRepositoryInterface
+	productRepo     repository.ProductRepositoryInterface
+	locationRepo    repository.LocationRepositoryInterface
+	defaultLocation string
+	timezone        *time.Location
+}
+
+// NewBatchService creates the batch service. Goods receipts without a
+// location are booked at the defaultLocation code, and batches expire at the
+// end of their expiry date in the store's timezone.
+func NewBatchService(batchRepo repository.BatchRepositoryInterface, stockRepo repository.StockRepositoryInterface, productRepo repository.ProductRepositoryInterface, locationRepo repository.LocationRepositoryInterface, defaultLocation string, timezone *time.Location) BatchServiceInterface {
+	return &batchService{
+		batchRepo:       batchRepo,
+		stockRepo:       stockRepo,
+		productRepo:     productRepo,
+		locationRepo:    locationRepo,
+		defaultLocation: defaultLocation,
+		timezone:        timezone,
+	}
+}
+
+// ReceiveGoods creates a batch for every received item and adds its quantity
+// to the location's stock.
+func (s *batchService) ReceiveGoods(receipt *model.GoodsReceipt) error {
+	if len(receipt.Items) == 0 {
+		return errors.New("items are required")
+	}
+	if receipt.LocationID == 0 {
+		location, err := s.locationRepo.GetByCode(s.defaultLocation)
+		if err != nil {
+			return err
+		}
+		receipt.LocationID = location.ID
+	} else {
+		_, err := s.locationRepo.GetByID(receipt.LocationID)
+		if err != nil {
+			return err
+		}
+	}
+	receipt.Supplier = strings.TrimSpace(receipt.Supplier)
+	receipt.Reference = strings.TrimSpace(receipt.Reference)
+	for i := range receipt.Items {
+		item := &receipt.Items[i]
+		item.BatchNumber = strings.TrimSpace(item.BatchNumber)
+		if item.BatchNumber == "" {
+			return errors.New("batch number is required")
+		}
+		if item.Quantity <= 0 {
+			return errors.New("quantity must be greater than zero")
+		}
+		if item.UnitCost < 0 {
+			return errors.New("unit cost must not be negative")
+		}
+		if item.ExpiryDate != nil {
+			_, err := time.Parse("2006-01-02", *item.ExpiryDate)
+			if err != nil {
+				return errors.New("expiry date must use the YYYY-MM-DD format")
+			}
+		}
+	}
+
+	tx, err := s.batchRepo.BeginTrans()
+	if err != nil {
+		return err
+	}
+	for i := range receipt.Items {
+		product, err := s.productRepo.LockByID(tx, receipt.Items[i].ProductID)
+		if err != nil {
+			s.batchRepo.RollbackTrans(tx)
+			return err
+		}
+		receipt.Items[i].ProductName = product.Name
+		err = s.stockRepo.Adjust(tx, receipt.LocationID, product.ID, receipt.Items[i].Quantity)
+		if err != nil {
+			s.batchRepo.RollbackTrans(tx)
+			return err
+		}
+	}
+	err = s.batchRepo.CreateReceipt(tx, receipt)
+	if err != nil {
+		s.batchRepo.RollbackTrans(tx)
+		return err
+	}
+	return s.batchRepo.CommitTrans(tx)
+}
+
+func (s *batchService) GetReceipts(locationID int) ([]model.GoodsReceipt, error) {
+	return s.batchRepo.GetReceipts(locationID)
+}
+
+func (s *batchService) GetReceiptByID(id int) (*model.GoodsReceipt, error) {
+	return s.batchRepo.GetReceiptByID(id)
+}
+
+func (s *batchService) GetBatches(productID int, locationID int, includeEmpty bool) ([]model.Batch, error) {
+	_, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	return s.batchRepo.GetByProduct(productID, locationID, includeEmpty)
+}
+
+func (s *batchService) GetExpiring(days int, locationID int) ([]model.ExpiringBatch, error) {
+	if days < 0 {
+		return nil, errors.New("days must not be negative")
+	}
+	if days == 0 {
+		days = 30
+	}
+	return s.batchRepo.GetExpiring(s.today(), days, locationID)
+}
+
+// Allocate takes quantity of a product out of its batches at a location,
+// earliest expiry first (FEFO). Stock that is not covered by any batch, such
+// as stock from before batch tracking, is used after the batches and is not
+// recorded in the allocations. Expired batches are never allocated. The
+// caller still has to adjust the stock level itself.
+func (s *batchService) Allocate(tx *sql.Tx, productID int, locationID int, quantity int) ([]model.BatchAllocation, error) {
+	level, err := s.stockRepo.LockLevel(tx, locationID, productID)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := s.batchRepo.LockSellable(tx, productID, locationID, s.today())
+	if err != nil {
+		return nil, err
+	}
+	tracked, err := s.batchRepo.GetTrackedQuantity(tx, productID, locationID)
+	if err != nil {
+		return nil, err
+	}
+	untracked := level - tracked
+	if untracked < 0 {
+		untracked = 0
+	}
+
+	allocations := make([]model.BatchAllocation, 0)
+	remaining := quantity
+	for _, batch := range batches {
+		if remaining == 0 {
+			break
+		}
+		take := batch.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		allocations = append(allocations, model.BatchAllocation{
+			BatchID:     batch.ID,
+			BatchNumber: batch.BatchNumber,
+			ExpiryDate:  batch.ExpiryDate,
+			Quantity:    take,
+		})
+		remaining -= take
+	}
+	if remaining > untracked {
+		return nil, repository.ErrBatchStockNotEnough
+	}
+
+	for _, allocation := range allocations {
+		err = s.batchRepo.AdjustQuantity(tx, allocation.BatchID, -allocation.Quantity)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return allocations, nil
+}
+
+// Release puts allocated quantities back into their batches.
+func (s *batchService) Release(tx *sql.Tx, allocations []model.BatchAllocation) error {
+	for _, allocation := range allocations {
+		err := s.batchRepo.AdjustQuantity(tx, allocation.BatchID, allocation.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// today is the store's current date in YYYY-MM-DD format.
+func (s *batchService) today() string {
+	return period.StartOfDay(time.Now(), s.timezone).Format(period.DateLayout)
+}
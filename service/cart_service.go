@@ -0,0 +1,377 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCartNotOpen   = errors.New("keranjang tidak sedang aktif")
+	ErrCartNotParked = errors.New("keranjang tidak sedang diparkir")
+	ErrCartClosed    = errors.New("keranjang sudah di-checkout atau dibuang")
+)
+
+type CartServiceInterface interface {
+	Create(cart *model.Cart) (*model.Cart, error)
+	GetAll(cashier string, status string) ([]model.Cart, error)
+	GetByID(id int) (*model.Cart, error)
+	AddItem(id int, item model.CheckoutItem) (*model.Cart, error)
+	UpdateItem(id int, item model.CheckoutItem) (*model.Cart, error)
+	RemoveItem(id int, productID int) (*model.Cart, error)
+	Park(id int) (*model.Cart, error)
+	Resume(id int, request *model.ResumeCartRequest) (*model.Cart, error)
+	Discard(id int) error
+	Checkout(id int, request *model.CartCheckoutRequest) (model.Transaction, error)
+}
+
+type cartService struct {
+	cartRepo           repository.CartRepositoryInterface
+	reservationRepo    repository.ReservationRepositoryInterface
+	productRepo        repository.ProductRepositoryInterface
+	customerRepo       repository.CustomerRepositoryInterface
+	transactionService TransactionServiceInterface
+	salesFeed          SalesFeedInterface
+	reservationTTL     time.Duration
+}
+
+func NewCartService(cartRepo repository.CartRepositoryInterface, reservationRepo repository.ReservationRepositoryInterface, productRepo repository.ProductRepositoryInterface, customerRepo repository.CustomerRepositoryInterface, transactionService TransactionServiceInterface, salesFeed SalesFeedInterface, reservationTTL time.Duration) CartServiceInterface {
+	return &cartService{
+		cartRepo:           cartRepo,
+		reservationRepo:    reservationRepo,
+		productRepo:        productRepo,
+		customerRepo:       customerRepo,
+		transactionService: transactionService,
+		salesFeed:          salesFeed,
+		reservationTTL:     reservationTTL,
+	}
+}
+
+func (s *cartService) Create(cart *model.Cart) (*model.Cart, error) {
+	cart.Cashier = strings.TrimSpace(cart.Cashier)
+	if cart.Cashier == "" {
+		return nil, errors.New("cashier is required")
+	}
+	if cart.CustomerID != nil {
+		_, err := s.customerRepo.GetByID(*cart.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cart.Status = model.CartStatusOpen
+	err := s.cartRepo.Create(cart)
+	if err != nil {
+		return nil, err
+	}
+	return s.cartRepo.GetByID(cart.ID)
+}
+
+func (s *cartService) GetAll(cashier string, status string) ([]model.Cart, error) {
+	switch status {
+	case "", model.CartStatusOpen, model.CartStatusParked, model.CartStatusCheckedOut, model.CartStatusDiscarded:
+	default:
+		return nil, errors.New("status must be open, parked, checked_out or discarded")
+	}
+	return s.cartRepo.GetAll(cashier, status)
+}
+
+func (s *cartService) GetByID(id int) (*model.Cart, error) {
+	return s.cartRepo.GetByID(id)
+}
+
+// AddItem adds the quantity to the line of the product, creating the line if
+// the cart does not contain the product yet.
+func (s *cartService) AddItem(id int, item model.CheckoutItem) (*model.Cart, error) {
+	if item.Quantity <= 0 {
+		return nil, errors.New("quantity must be greater than zero")
+	}
+	return s.changeLine(id, item.ProductID, func(current int) int {
+		return current + item.Quantity
+	})
+}
+
+// UpdateItem sets the quantity of a line; a quantity of zero removes it.
+func (s *cartService) UpdateItem(id int, item model.CheckoutItem) (*model.Cart, error) {
+	if item.Quantity < 0 {
+		return nil, errors.New("quantity must not be negative")
+	}
+	if item.Quantity == 0 {
+		return s.RemoveItem(id, item.ProductID)
+	}
+	return s.changeLine(id, item.ProductID, func(current int) int {
+		return item.Quantity
+	})
+}
+
+func (s *cartService) RemoveItem(id int, productID int) (*model.Cart, error) {
+	tx, err := s.cartRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	cart, err := s.lockOpen(tx, id)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.cartRepo.RemoveItem(tx, id, productID)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.reservationRepo.Release(tx, id, productID)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.touch(tx, cart)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.cartRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	return s.cartRepo.GetByID(id)
+}
+
+// changeLine applies quantity to the cart line of productID. Stock held by
+// other reservations is not available to this cart; when the cart reserves
+// stock itself, its own reservation is updated as well.
+func (s *cartService) changeLine(id int, productID int, quantity func(current int) int) (*model.Cart, error) {
+	tx, err := s.cartRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	cart, err := s.lockOpen(tx, id)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	product, err := s.productRepo.LockByID(tx, productID)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	items, err := s.cartRepo.GetItems(id)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	current := 0
+	for _, item := range items {
+		if item.ProductID == productID {
+			current = item.Quantity
+		}
+	}
+	newQuantity := quantity(current)
+
+	reserved, err := s.reservationRepo.GetReserved(tx, productID, id, "")
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	if product.Stock-reserved < newQuantity {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, errors.New("product stock not enough")
+	}
+
+	err = s.cartRepo.SetItem(tx, id, productID, newQuantity)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	if cart.ReserveStock {
+		err = s.reservationRepo.Reserve(tx, id, productID, newQuantity, time.Now().Add(s.reservationTTL))
+		if err != nil {
+			s.cartRepo.RollbackTrans(tx)
+			return nil, err
+		}
+	}
+	err = s.touch(tx, cart)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.cartRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	return s.cartRepo.GetByID(id)
+}
+
+func (s *cartService) Park(id int) (*model.Cart, error) {
+	tx, err := s.cartRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	cart, err := s.lockOpen(tx, id)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	cart.Status = model.CartStatusParked
+	err = s.touch(tx, cart)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.cartRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	return s.cartRepo.GetByID(id)
+}
+
+// Resume reopens a parked cart, optionally handing it over to another
+// cashier.
+func (s *cartService) Resume(id int, request *model.ResumeCartRequest) (*model.Cart, error) {
+	tx, err := s.cartRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	cart, err := s.cartRepo.Lock(tx, id)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	if cart.Status != model.CartStatusParked {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, ErrCartNotParked
+	}
+	cart.Status = model.CartStatusOpen
+	if cashier := strings.TrimSpace(request.Cashier); cashier != "" {
+		cart.Cashier = cashier
+	}
+	err = s.touch(tx, cart)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.cartRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	return s.cartRepo.GetByID(id)
+}
+
+// Discard abandons an open or parked cart and releases its reservations.
+func (s *cartService) Discard(id int) error {
+	tx, err := s.cartRepo.BeginTrans()
+	if err != nil {
+		return err
+	}
+	cart, err := s.lockActive(tx, id)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return err
+	}
+	cart.Status = model.CartStatusDiscarded
+	err = s.cartRepo.Update(tx, cart)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return err
+	}
+	err = s.reservationRepo.ReleaseCart(tx, id)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return err
+	}
+	return s.cartRepo.CommitTrans(tx)
+}
+
+// Checkout converts an open or parked cart into a transaction. The sale, which
+// also consumes the cart's reservations, and the cart status are committed
+// together.
+func (s *cartService) Checkout(id int, request *model.CartCheckoutRequest) (model.Transaction, error) {
+	tx, err := s.cartRepo.BeginTrans()
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	cart, err := s.lockActive(tx, id)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return model.Transaction{}, err
+	}
+	items, err := s.cartRepo.GetItems(id)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return model.Transaction{}, err
+	}
+	if len(items) == 0 {
+		s.cartRepo.RollbackTrans(tx)
+		return model.Transaction{}, errors.New("cart is empty")
+	}
+
+	checkoutRequest := model.CheckoutRequest{
+		Cashier:      cart.Cashier,
+		CustomerID:   cart.CustomerID,
+		RedeemPoints: request.RedeemPoints,
+		Payments:     request.Payments,
+		CartID:       cart.ID,
+	}
+	if cashier := strings.TrimSpace(request.Cashier); cashier != "" {
+		checkoutRequest.Cashier = cashier
+	}
+	for _, item := range items {
+		checkoutRequest.Items = append(checkoutRequest.Items, item.CheckoutItem)
+	}
+
+	transaction, err := s.transactionService.CheckoutTx(tx, &checkoutRequest)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return model.Transaction{}, err
+	}
+
+	cart.Status = model.CartStatusCheckedOut
+	cart.TransactionID = &transaction.ID
+	err = s.cartRepo.Update(tx, cart)
+	if err != nil {
+		s.cartRepo.RollbackTrans(tx)
+		return model.Transaction{}, err
+	}
+	err = s.cartRepo.CommitTrans(tx)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	s.salesFeed.Publish(model.SalesEventCheckout, transaction)
+	return transaction, nil
+}
+
+func (s *cartService) lockOpen(tx *sql.Tx, id int) (*model.Cart, error) {
+	cart, err := s.lockActive(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cart.Status != model.CartStatusOpen {
+		return nil, ErrCartNotOpen
+	}
+	return cart, nil
+}
+
+func (s *cartService) lockActive(tx *sql.Tx, id int) (*model.Cart, error) {
+	cart, err := s.cartRepo.Lock(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cart.Status == model.CartStatusCheckedOut || cart.Status == model.CartStatusDiscarded {
+		return nil, ErrCartClosed
+	}
+	return cart, nil
+}
+
+// touch saves the cart and, for carts that reserve stock, restarts the
+// reservation timeout since the cart is still being worked on.
+func (s *cartService) touch(tx *sql.Tx, cart *model.Cart) error {
+	err := s.cartRepo.Update(tx, cart)
+	if err != nil {
+		return err
+	}
+	if !cart.ReserveStock {
+		return nil
+	}
+	return s.reservationRepo.Extend(tx, cart.ID, time.Now().Add(s.reservationTTL))
+}
@@ -1,16 +1,20 @@
 package service
 
 import (
+	"errors"
 	"product-api/model"
 	"product-api/repository"
 )
 
+var ErrCategoryInUse = errors.New("category still has products")
+
 type CategoryServiceInterface interface {
-	GetAll() ([]model.Category, error)
+	GetAll(includeDeleted bool) ([]model.Category, error)
 	Create(category *model.Category) error
 	GetByID(id int) (*model.Category, error)
 	Update(category *model.Category) error
 	Delete(id int) error
+	Restore(id int) (*model.Category, error)
 }
 
 type categoryService struct {
@@ -21,8 +25,8 @@ func NewCategoryService(categoryRepo repository.CategoryRepositoryInterface) Cat
 	return &categoryService{categoryRepo: categoryRepo}
 }
 
-func (s *categoryService) GetAll() ([]model.Category, error) {
-	return s.categoryRepo.GetAll()
+func (s *categoryService) GetAll(includeDeleted bool) ([]model.Category, error) {
+	return s.categoryRepo.GetAll(includeDeleted)
 }
 
 func (s *categoryService) Create(category *model.Category) error {
@@ -38,5 +42,20 @@ func (s *categoryService) Update(category *model.Category) error {
 }
 
 func (s *categoryService) Delete(id int) error {
+	inUse, err := s.categoryRepo.HasActiveProducts(id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrCategoryInUse
+	}
 	return s.categoryRepo.Delete(id)
-}
\ No newline at end of file
+}
+
+func (s *categoryService) Restore(id int) (*model.Category, error) {
+	err := s.categoryRepo.Restore(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.categoryRepo.GetByID(id)
+}
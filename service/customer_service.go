@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/utils/phone"
+	"strings"
+)
+
+type CustomerServiceInterface interface {
+	GetAll(search string) ([]model.Customer, error)
+	Create(customer *model.Customer) error
+	GetByID(id int) (*model.Customer, error)
+	Update(customer *model.Customer) error
+	Delete(id int) error
+	GetTransactions(id int) (model.CustomerTransactionsResponse, error)
+	GetPoints(id int) (model.PointsResponse, error)
+}
+
+type customerService struct {
+	customerRepo    repository.CustomerRepositoryInterface
+	transactionRepo repository.TransactionRepositoryInterface
+	loyaltyService  LoyaltyServiceInterface
+}
+
+func NewCustomerService(customerRepo repository.CustomerRepositoryInterface, transactionRepo repository.TransactionRepositoryInterface, loyaltyService LoyaltyServiceInterface) CustomerServiceInterface {
+	return &customerService{customerRepo: customerRepo, transactionRepo: transactionRepo, loyaltyService: loyaltyService}
+}
+
+func (s *customerService) GetAll(search string) ([]model.Customer, error) {
+	return s.customerRepo.GetAll(search)
+}
+
+func (s *customerService) Create(customer *model.Customer) error {
+	err := s.normalize(customer)
+	if err != nil {
+		return err
+	}
+	return s.customerRepo.Create(customer)
+}
+
+func (s *customerService) GetByID(id int) (*model.Customer, error) {
+	customer, err := s.customerRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	customer.Points, err = s.loyaltyService.GetBalance(id)
+	if err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+func (s *customerService) Update(customer *model.Customer) error {
+	err := s.normalize(customer)
+	if err != nil {
+		return err
+	}
+	return s.customerRepo.Update(customer)
+}
+
+func (s *customerService) Delete(id int) error {
+	return s.customerRepo.Delete(id)
+}
+
+func (s *customerService) GetTransactions(id int) (model.CustomerTransactionsResponse, error) {
+	customer, err := s.GetByID(id)
+	if err != nil {
+		return model.CustomerTransactionsResponse{}, err
+	}
+	transactions, err := s.transactionRepo.GetByCustomerID(id)
+	if err != nil {
+		return model.CustomerTransactionsResponse{}, err
+	}
+
+	response := model.CustomerTransactionsResponse{
+		Customer:     customer,
+		Transactions: transactions,
+	}
+	for _, transaction := range transactions {
+		if transaction.RefundedAt != nil {
+			continue
+		}
+		response.TotalTransaction += 1
+		response.LifetimeSpend += transaction.TotalAmount
+	}
+	return response, nil
+}
+
+func (s *customerService) GetPoints(id int) (model.PointsResponse, error) {
+	_, err := s.customerRepo.GetByID(id)
+	if err != nil {
+		return model.PointsResponse{}, err
+	}
+	return s.loyaltyService.GetPoints(id)
+}
+
+func (s *customerService) normalize(customer *model.Customer) error {
+	customer.Name = strings.TrimSpace(customer.Name)
+	customer.Email = strings.TrimSpace(customer.Email)
+	if customer.Name == "" {
+		return errors.New("customer name is required")
+	}
+	if customer.Email != "" && !strings.Contains(customer.Email, "@") {
+		return errors.New("invalid email address")
+	}
+	normalized, err := phone.Normalize(customer.Phone)
+	if err != nil {
+		return err
+	}
+	customer.Phone = normalized
+	return nil
+}
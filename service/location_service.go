@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"strings"
+)
+
+type LocationServiceInterface interface {
+	GetAll() ([]model.Location, error)
+	Create(location *model.Location) error
+	GetByID(id int) (*model.Location, error)
+	Update(location *model.Location) error
+	GetStock(id int) ([]model.StockLevel, error)
+	SetStock(id int, productID int, quantity int) (*model.StockLevel, error)
+}
+
+type locationService struct {
+	locationRepo repository.LocationRepositoryInterface
+	stockRepo    repository.StockRepositoryInterface
+	productRepo  repository.ProductRepositoryInterface
+}
+
+func NewLocationService(locationRepo repository.LocationRepositoryInterface, stockRepo repository.StockRepositoryInterface, productRepo repository.ProductRepositoryInterface) LocationServiceInterface {
+	return &locationService{locationRepo: locationRepo, stockRepo: stockRepo, productRepo: productRepo}
+}
+
+func (s *locationService) GetAll() ([]model.Location, error) {
+	return s.locationRepo.GetAll()
+}
+
+func (s *locationService) Create(location *model.Location) error {
+	err := normalizeLocation(location)
+	if err != nil {
+		return err
+	}
+	return s.locationRepo.Create(location)
+}
+
+func (s *locationService) GetByID(id int) (*model.Location, error) {
+	return s.locationRepo.GetByID(id)
+}
+
+func (s *locationService) Update(location *model.Location) error {
+	err := normalizeLocation(location)
+	if err != nil {
+		return err
+	}
+	return s.locationRepo.Update(location)
+}
+
+func (s *locationService) GetStock(id int) ([]model.StockLevel, error) {
+	_, err := s.locationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.stockRepo.GetByLocation(id)
+}
+
+// SetStock overwrites the stock of a product at a location, e.g. for the
+// opening balance of a new outlet. The product total follows the difference.
+func (s *locationService) SetStock(id int, productID int, quantity int) (*model.StockLevel, error) {
+	if quantity < 0 {
+		return nil, errors.New("quantity must not be negative")
+	}
+	location, err := s.locationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.productRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	product, err := s.productRepo.LockByID(tx, productID)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	current, err := s.stockRepo.LockLevel(tx, id, productID)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.stockRepo.Adjust(tx, id, productID, quantity-current)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	if quantity < current {
+		err = s.stockRepo.TrimBatches(tx, id, productID)
+		if err != nil {
+			s.productRepo.RollbackTrans(tx)
+			return nil, err
+		}
+	}
+	err = s.productRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StockLevel{
+		LocationID:   location.ID,
+		LocationCode: location.Code,
+		ProductID:    product.ID,
+		ProductName:  product.Name,
+		Quantity:     quantity,
+	}, nil
+}
+
+func normalizeLocation(location *model.Location) error {
+	location.Code = strings.ToUpper(strings.TrimSpace(location.Code))
+	location.Name = strings.TrimSpace(location.Name)
+	if location.Code == "" {
+		return errors.New("code is required")
+	}
+	if location.Name == "" {
+		return errors.New("name is required")
+	}
+	if location.Type == "" {
+		location.Type = model.LocationTypeOutlet
+	}
+	if location.Type != model.LocationTypeOutlet && location.Type != model.LocationTypeWarehouse {
+		return errors.New("type must be outlet or warehouse")
+	}
+	return nil
+}
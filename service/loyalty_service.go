@@ -0,0 +1,163 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"time"
+)
+
+var ErrInsufficientPoints = errors.New("loyalty points not enough")
+
+type LoyaltyServiceInterface interface {
+	RedeemValue(points int) int
+	Earn(tx *sql.Tx, customerID int, transactionID int, amount int) (int, error)
+	Redeem(tx *sql.Tx, customerID int, transactionID int, points int) error
+	Reverse(tx *sql.Tx, transactionID int) error
+	GetBalance(customerID int) (int, error)
+	GetPoints(customerID int) (model.PointsResponse, error)
+}
+
+type loyaltyService struct {
+	loyaltyRepo repository.LoyaltyRepositoryInterface
+	rule        model.LoyaltyRule
+}
+
+func NewLoyaltyService(loyaltyRepo repository.LoyaltyRepositoryInterface, rule model.LoyaltyRule) LoyaltyServiceInterface {
+	return &loyaltyService{loyaltyRepo: loyaltyRepo, rule: rule}
+}
+
+func (s *loyaltyService) RedeemValue(points int) int {
+	return points * s.rule.RedeemValue
+}
+
+func (s *loyaltyService) Earn(tx *sql.Tx, customerID int, transactionID int, amount int) (int, error) {
+	if s.rule.EarnAmount <= 0 {
+		return 0, nil
+	}
+	// Matured points are written off before the new ones are added
+	_, err := s.balance(tx, customerID)
+	if err != nil {
+		return 0, err
+	}
+	points := amount / s.rule.EarnAmount
+	if points <= 0 {
+		return 0, nil
+	}
+
+	entry := model.PointEntry{
+		CustomerID:    customerID,
+		TransactionID: &transactionID,
+		Type:          model.PointEntryEarn,
+		Points:        points,
+	}
+	if s.rule.ExpiryDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, s.rule.ExpiryDays)
+		entry.ExpiresAt = &expiresAt
+	}
+	err = s.loyaltyRepo.Create(tx, &entry)
+	if err != nil {
+		return 0, err
+	}
+	return points, nil
+}
+
+func (s *loyaltyService) Redeem(tx *sql.Tx, customerID int, transactionID int, points int) error {
+	balance, err := s.balance(tx, customerID)
+	if err != nil {
+		return err
+	}
+	if balance < points {
+		return ErrInsufficientPoints
+	}
+	return s.loyaltyRepo.Create(tx, &model.PointEntry{
+		CustomerID:    customerID,
+		TransactionID: &transactionID,
+		Type:          model.PointEntryRedeem,
+		Points:        -points,
+	})
+}
+
+// Reverse writes refund entries that cancel every earn and redeem entry of
+// the transaction, so redeemed points go back to the customer and earned
+// points are taken back.
+func (s *loyaltyService) Reverse(tx *sql.Tx, transactionID int) error {
+	entries, err := s.loyaltyRepo.GetByTransactionID(tx, transactionID)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Type != model.PointEntryEarn && entry.Type != model.PointEntryRedeem {
+			continue
+		}
+		err = s.loyaltyRepo.Create(tx, &model.PointEntry{
+			CustomerID:    entry.CustomerID,
+			TransactionID: &transactionID,
+			Type:          model.PointEntryRefund,
+			Points:        -entry.Points,
+			ExpiresAt:     entry.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBalance returns the spendable balance without writing to the ledger.
+// Expired points are left out here and written off by the next earn or
+// redeem of the customer.
+func (s *loyaltyService) GetBalance(customerID int) (int, error) {
+	balance, expired, err := s.loyaltyRepo.ReadBalance(customerID)
+	if err != nil {
+		return 0, err
+	}
+	if expired > balance {
+		expired = balance
+	}
+	if expired <= 0 {
+		return balance, nil
+	}
+	return balance - expired, nil
+}
+
+func (s *loyaltyService) GetPoints(customerID int) (model.PointsResponse, error) {
+	balance, err := s.GetBalance(customerID)
+	if err != nil {
+		return model.PointsResponse{}, err
+	}
+	entries, err := s.loyaltyRepo.GetByCustomerID(customerID)
+	if err != nil {
+		return model.PointsResponse{}, err
+	}
+	return model.PointsResponse{CustomerID: customerID, Balance: balance, Entries: entries}, nil
+}
+
+// balance expires matured points before returning the spendable balance.
+func (s *loyaltyService) balance(tx *sql.Tx, customerID int) (int, error) {
+	balance, err := s.loyaltyRepo.GetBalance(tx, customerID)
+	if err != nil {
+		return 0, err
+	}
+	expired, err := s.loyaltyRepo.GetExpiredPoints(tx, customerID)
+	if err != nil {
+		return 0, err
+	}
+	if expired > balance {
+		expired = balance
+	}
+	if expired <= 0 {
+		return balance, nil
+	}
+
+	err = s.loyaltyRepo.Create(tx, &model.PointEntry{
+		CustomerID: customerID,
+		Type:       model.PointEntryExpire,
+		Points:     -expired,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return balance - expired, nil
+}
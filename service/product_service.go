@@ -4,35 +4,66 @@ import (
 	"errors"
 	"product-api/model"
 	"product-api/repository"
+	"time"
 )
 
+// ErrCategoryDeleted means a product cannot be restored because its
+// category is still deleted.
+var ErrCategoryDeleted = errors.New("category of the product is deleted")
+
 type ProductServiceInterface interface {
-	GetAll(name string) ([]model.Product, error)
+	GetAll(name string, includeDeleted bool) ([]model.Product, error)
 	Create(data *model.Product) error
 	GetByID(id int) (*model.Product, error)
 	Update(product *model.Product) error
 	Delete(id int) error
+	Restore(id int) (*model.Product, error)
+	GetPrices(productID int) ([]model.ProductPrice, error)
+	SchedulePrice(productID int, request *model.SchedulePriceRequest) (*model.ProductPrice, error)
+	CancelScheduledPrice(productID int, priceID int) error
+	GetStock(productID int) (*model.ProductStock, error)
 }
 
 type productService struct {
-	productRepo  repository.ProductRepositoryInterface
-	categoryRepo repository.CategoryRepositoryInterface
+	productRepo     repository.ProductRepositoryInterface
+	categoryRepo    repository.CategoryRepositoryInterface
+	priceRepo       repository.ProductPriceRepositoryInterface
+	stockRepo       repository.StockRepositoryInterface
+	locationRepo    repository.LocationRepositoryInterface
+	defaultLocation string
 }
 
-func NewProductService(productRepo repository.ProductRepositoryInterface, categoryRepo repository.CategoryRepositoryInterface) ProductServiceInterface {
-	return &productService{productRepo: productRepo, categoryRepo: categoryRepo}
+// NewProductService creates the product service. Stock given on the product
+// endpoints is booked at the defaultLocation code.
+func NewProductService(productRepo repository.ProductRepositoryInterface, categoryRepo repository.CategoryRepositoryInterface, priceRepo repository.ProductPriceRepositoryInterface, stockRepo repository.StockRepositoryInterface, locationRepo repository.LocationRepositoryInterface, defaultLocation string) ProductServiceInterface {
+	return &productService{
+		productRepo:     productRepo,
+		categoryRepo:    categoryRepo,
+		priceRepo:       priceRepo,
+		stockRepo:       stockRepo,
+		locationRepo:    locationRepo,
+		defaultLocation: defaultLocation,
+	}
 }
 
-func (s *productService) GetAll(name string) ([]model.Product, error) {
-	products, err := s.productRepo.GetAll(name)
+func (s *productService) GetAll(name string, includeDeleted bool) ([]model.Product, error) {
+	products, err := s.productRepo.GetAll(name, includeDeleted)
 	if err != nil {
 		return nil, err
 	}
-	var result []model.Product
+	categories, err := s.categoryRepo.GetAll(true)
+	if err != nil {
+		return nil, err
+	}
+	categoryByID := make(map[int]model.Category, len(categories))
+	for _, category := range categories {
+		categoryByID[category.Id] = category
+	}
+
+	result := make([]model.Product, 0, len(products))
 	for _, product := range products {
-		product.Category, err = s.categoryRepo.GetByID(product.CategoryID)
-		if err != nil {
-			return nil, err
+		if category, ok := categoryByID[product.CategoryID]; ok {
+			product.Category = &category
 		}
 		result = append(result, product)
 	}
@@ -44,11 +75,37 @@ func (s *productService) Create(data *model.Product) error {
 	if err != nil {
 		return errors.New("category not found")
 	}
-	err = s.productRepo.Create(data)
+	if data.Stock < 0 {
+		return errors.New("stock must not be negative")
+	}
+	location, err := s.locationRepo.GetByCode(s.defaultLocation)
+	if err != nil {
+		return err
+	}
+	tx, err := s.productRepo.BeginTrans()
+	if err != nil {
+		return err
+	}
+	// The stock is added through the default location's stock level
+	stock := data.Stock
+	data.Stock = 0
+	err = s.productRepo.Create(tx, data)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return err
+	}
+	err = s.stockRepo.Adjust(tx, location.ID, data.ID, stock)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return err
+	}
+	data.Stock = stock
+	err = s.priceRepo.Create(tx, &model.ProductPrice{ProductID: data.ID, Price: data.Price})
 	if err != nil {
+		s.productRepo.RollbackTrans(tx)
 		return err
 	}
-	return nil
+	return s.productRepo.CommitTrans(tx)
 }
 
 func (s *productService) GetByID(id int) (*model.Product, error) {
@@ -68,17 +125,157 @@ func (s *productService) Update(product *model.Product) error {
 	if err != nil {
 		return errors.New("category not found")
 	}
-	tx, _ := s.productRepo.BeginTrans()
+	location, err := s.locationRepo.GetByCode(s.defaultLocation)
+	if err != nil {
+		return err
+	}
+	tx, err := s.productRepo.BeginTrans()
+	if err != nil {
+		return err
+	}
+	current, err := s.productRepo.LockByID(tx, product.ID)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return err
+	}
+	// A stock change is booked at the default location
+	if product.Stock != current.Stock {
+		err = s.stockRepo.Adjust(tx, location.ID, product.ID, product.Stock-current.Stock)
+		if err != nil {
+			s.productRepo.RollbackTrans(tx)
+			return err
+		}
+	}
+	if product.Stock < current.Stock {
+		err = s.stockRepo.TrimBatches(tx, location.ID, product.ID)
+		if err != nil {
+			s.productRepo.RollbackTrans(tx)
+			return err
+		}
+	}
 	err = s.productRepo.Update(tx, product)
 	if err != nil {
 		s.productRepo.RollbackTrans(tx)
 		return err
 	}
+	if current.Price != product.Price {
+		err = s.priceRepo.Create(tx, &model.ProductPrice{ProductID: product.ID, Price: product.Price})
+		if err != nil {
+			s.productRepo.RollbackTrans(tx)
+			return err
+		}
+	}
 
-	s.productRepo.CommitTrans(tx)
-	return nil
+	return s.productRepo.CommitTrans(tx)
 }
 
 func (s *productService) Delete(id int) error {
 	return s.productRepo.Delete(id)
 }
+
+func (s *productService) Restore(id int) (*model.Product, error) {
+	tx, err := s.productRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	product, err := s.productRepo.Restore(tx, id)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	product.Category, err = s.categoryRepo.GetByID(product.CategoryID)
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		s.productRepo.RollbackTrans(tx)
+		return nil, ErrCategoryDeleted
+	}
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return nil, err
+	}
+
+	err = s.productRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *productService) GetPrices(productID int) ([]model.ProductPrice, error) {
+	_, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	return s.priceRepo.GetByProductID(productID)
+}
+
+func (s *productService) SchedulePrice(productID int, request *model.SchedulePriceRequest) (*model.ProductPrice, error) {
+	if request.Price < 0 {
+		return nil, errors.New("price must not be negative")
+	}
+	immediate := request.EffectiveFrom == nil
+	if !immediate && !request.EffectiveFrom.After(time.Now()) {
+		return nil, errors.New("effective_from must be in the future")
+	}
+
+	_, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.productRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	price := model.ProductPrice{ProductID: productID, Price: request.Price}
+	if !immediate {
+		price.EffectiveFrom = *request.EffectiveFrom
+	}
+	err = s.priceRepo.Create(tx, &price)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	if immediate {
+		product, err := s.productRepo.LockByID(tx, productID)
+		if err != nil {
+			s.productRepo.RollbackTrans(tx)
+			return nil, err
+		}
+		product.Price = request.Price
+		err = s.productRepo.Update(tx, product)
+		if err != nil {
+			s.productRepo.RollbackTrans(tx)
+			return nil, err
+		}
+	}
+
+	err = s.productRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+func (s *productService) CancelScheduledPrice(productID int, priceID int) error {
+	return s.priceRepo.DeleteScheduled(productID, priceID)
+}
+
+func (s *productService) GetStock(productID int) (*model.ProductStock, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := s.stockRepo.GetByProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+	inTransit, err := s.stockRepo.GetInTransit(productID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ProductStock{
+		ProductID: product.ID,
+		Stock:     product.Stock,
+		InTransit: inTransit,
+		Locations: levels,
+	}, nil
+}
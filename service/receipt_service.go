@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/utils/receipt"
+	"time"
+)
+
+type ReceiptServiceInterface interface {
+	Render(transactionID int, format string, paper int, openDrawer bool) ([]byte, string, error)
+	GetSettings() (*model.ReceiptSettings, error)
+	UpdateSettings(settings *model.ReceiptSettings) error
+	GetTemplate(format string) (*model.ReceiptTemplate, error)
+	UpdateTemplate(template *model.ReceiptTemplate) error
+	ResetTemplate(format string) error
+}
+
+type receiptService struct {
+	receiptRepo     repository.ReceiptRepositoryInterface
+	transactionRepo repository.TransactionRepositoryInterface
+	timezone        *time.Location
+}
+
+// NewReceiptService creates the receipt service. Receipts show the
+// transaction time in the store's timezone.
+func NewReceiptService(receiptRepo repository.ReceiptRepositoryInterface, transactionRepo repository.TransactionRepositoryInterface, timezone *time.Location) ReceiptServiceInterface {
+	return &receiptService{receiptRepo: receiptRepo, transactionRepo: transactionRepo, timezone: timezone}
+}
+
+// Render renders the receipt of a transaction. openDrawer only applies to
+// ESC/POS receipts.
+func (s *receiptService) Render(transactionID int, format string, paper int, openDrawer bool) ([]byte, string, error) {
+	width, err := receipt.Width(paper)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := s.receiptData(transactionID)
+	if err != nil {
+		return nil, "", err
+	}
+	data.Width = width
+	data.Paper = paper
+
+	switch format {
+	case model.ReceiptFormatText:
+		content, err := s.templateContent(model.ReceiptFormatText)
+		if err != nil {
+			return nil, "", err
+		}
+		body, err := receipt.RenderText(content, data)
+		return body, "text/plain; charset=utf-8", err
+	case model.ReceiptFormatHTML:
+		content, err := s.templateContent(model.ReceiptFormatHTML)
+		if err != nil {
+			return nil, "", err
+		}
+		body, err := receipt.RenderHTML(content, data)
+		return body, "text/html; charset=utf-8", err
+	case model.ReceiptFormatPDF:
+		content, err := s.templateContent(model.ReceiptFormatText)
+		if err != nil {
+			return nil, "", err
+		}
+		text, err := receipt.RenderText(content, data)
+		if err != nil {
+			return nil, "", err
+		}
+		body, err := receipt.RenderPDF(text, paper, width)
+		return body, "application/pdf", err
+	case model.ReceiptFormatESCPOS:
+		return receipt.RenderESCPOS(data, openDrawer), "application/octet-stream", nil
+	}
+	return nil, "", receipt.ErrUnsupportedFormat
+}
+
+func (s *receiptService) GetSettings() (*model.ReceiptSettings, error) {
+	return s.receiptRepo.GetSettings()
+}
+
+func (s *receiptService) UpdateSettings(settings *model.ReceiptSettings) error {
+	return s.receiptRepo.UpdateSettings(settings)
+}
+
+func (s *receiptService) GetTemplate(format string) (*model.ReceiptTemplate, error) {
+	template, err := s.receiptRepo.GetTemplate(format)
+	if errors.Is(err, repository.ErrReceiptTemplateNotFound) {
+		content, err := receipt.DefaultTemplate(format)
+		if err != nil {
+			return nil, err
+		}
+		return &model.ReceiptTemplate{Format: format, Content: content, IsDefault: true}, nil
+	}
+	return template, err
+}
+
+func (s *receiptService) UpdateTemplate(template *model.ReceiptTemplate) error {
+	err := receipt.Validate(template.Format, template.Content)
+	if err != nil {
+		return err
+	}
+	return s.receiptRepo.SaveTemplate(template)
+}
+
+func (s *receiptService) ResetTemplate(format string) error {
+	_, err := receipt.DefaultTemplate(format)
+	if err != nil {
+		return err
+	}
+	return s.receiptRepo.DeleteTemplate(format)
+}
+
+func (s *receiptService) templateContent(format string) (string, error) {
+	template, err := s.GetTemplate(format)
+	if err != nil {
+		return "", err
+	}
+	return template.Content, nil
+}
+
+func (s *receiptService) receiptData(transactionID int) (model.Receipt, error) {
+	transaction, err := s.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return model.Receipt{}, err
+	}
+	settings, err := s.receiptRepo.GetSettings()
+	if err != nil {
+		return model.Receipt{}, err
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, transaction.CreatedAt)
+	if err == nil {
+		transaction.CreatedAt = createdAt.In(s.timezone).Format(time.RFC3339Nano)
+	}
+
+	data := model.Receipt{Store: *settings, Transaction: *transaction}
+	for _, detail := range transaction.Details {
+		data.Subtotal += detail.Subtotal
+	}
+	return data, nil
+}
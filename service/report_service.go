@@ -0,0 +1,661 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/utils/export"
+	"product-api/utils/period"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidInterval    = errors.New("interval must be hour, day, week or month")
+	ErrHourlyRangeTooLong = errors.New("interval hour is limited to 31 days")
+	ErrInvalidRankBy      = errors.New("by must be quantity or revenue")
+	ErrInvalidLimit       = errors.New("limit must be between 1 and 100")
+	ErrInvalidGroup       = errors.New("group must be category or product")
+	ErrInvalidSortKey     = errors.New("sort must be name, quantity, gross_sales, discount, net_sales, tax or profit")
+	ErrInvalidSortOrder   = errors.New("order must be asc or desc")
+	ErrInvalidCompare     = errors.New("compare must be previous_period or last_year")
+	ErrInvalidMinCount    = errors.New("min_transactions must be at least 1")
+)
+
+const (
+	defaultTopLimit     = 10
+	defaultRelatedLimit = 5
+	maxTopLimit         = 100
+)
+
+// defaultMinPairCount leaves out pairs bought together only once, which say
+// little about buying habits.
+const defaultMinPairCount = 2
+
+// maxHourlyDays limits hourly time series so a long range does not return
+// thousands of buckets.
+const maxHourlyDays = 31
+
+type ReportServiceInterface interface {
+	Timeseries(filter model.ReportFilter, interval string) (*model.TimeseriesResponse, error)
+	TopProducts(filter model.ReportFilter, by string, limit int) (*model.TopResponse, error)
+	TopCategories(filter model.ReportFilter, by string, limit int) (*model.TopResponse, error)
+	Summary(filter model.ReportFilter, compare string) (model.SummaryResponse, error)
+	ExportSummary(filter model.ReportFilter, compare string, format string) ([]byte, string, error)
+	Transactions(filter model.ReportFilter) ([]model.Transaction, error)
+	ExportTransactions(filter model.ReportFilter, format string) ([]byte, string, error)
+	Breakdown(filter model.ReportFilter, group string, sortBy string, order string) (*model.SalesBreakdown, error)
+	ExportBreakdown(filter model.ReportFilter, group string, sortBy string, order string, format string) ([]byte, string, error)
+	Basket(filter model.ReportFilter, minTransactions int, limit int) (*model.BasketAnalysis, error)
+	Related(productID int, filter model.ReportFilter, limit int) (*model.RelatedProducts, error)
+	Inventory(filter model.ReportFilter, deadDays int, group string) (*model.InventoryReport, error)
+}
+
+type reportService struct {
+	reportRepo      repository.ReportRepositoryInterface
+	transactionRepo repository.TransactionRepositoryInterface
+	receiptRepo     repository.ReceiptRepositoryInterface
+	productRepo     repository.ProductRepositoryInterface
+}
+
+// NewReportService creates the report service. The store details printed on
+// PDF reports come from the receipt settings.
+func NewReportService(reportRepo repository.ReportRepositoryInterface, transactionRepo repository.TransactionRepositoryInterface, receiptRepo repository.ReceiptRepositoryInterface, productRepo repository.ProductRepositoryInterface) ReportServiceInterface {
+	return &reportService{
+		reportRepo:      reportRepo,
+		transactionRepo: transactionRepo,
+		receiptRepo:     receiptRepo,
+		productRepo:     productRepo,
+	}
+}
+
+// Summary covers the transactions of the filter. With compare set the
+// summary also holds the changes against the previous period of the same
+// length or the same period last year.
+func (s *reportService) Summary(filter model.ReportFilter, compare string) (model.SummaryResponse, error) {
+	previousFilter := filter
+	switch compare {
+	case model.CompareNone:
+	case model.ComparePreviousPeriod:
+		previousFilter.From, previousFilter.To = period.Previous(filter.From, filter.To)
+	case model.CompareLastYear:
+		previousFilter.From, previousFilter.To = period.LastYear(filter.From, filter.To)
+	default:
+		return model.SummaryResponse{}, ErrInvalidCompare
+	}
+
+	summary, err := s.summarize(filter)
+	if err != nil {
+		return model.SummaryResponse{}, err
+	}
+	if compare == model.CompareNone {
+		return summary, nil
+	}
+	previous, err := s.summarize(previousFilter)
+	if err != nil {
+		return model.SummaryResponse{}, err
+	}
+	summary.Comparison = &model.SummaryComparison{
+		Compare:          compare,
+		From:             previousFilter.From,
+		To:               previousFilter.To,
+		Revenue:          metricDelta(summary.TotalRevenue, previous.TotalRevenue),
+		TransactionCount: metricDelta(summary.TotalTransaction, previous.TotalTransaction),
+		AverageBasket:    metricDelta(summary.AverageBasket, previous.AverageBasket),
+	}
+	return summary, nil
+}
+
+func (s *reportService) summarize(filter model.ReportFilter) (model.SummaryResponse, error) {
+	summary, err := s.reportRepo.Summary(filter)
+	if err != nil {
+		return model.SummaryResponse{}, err
+	}
+	if summary.TotalTransaction > 0 {
+		summary.AverageBasket = summary.TotalRevenue / summary.TotalTransaction
+	}
+	return summary, nil
+}
+
+func metricDelta(current int, previous int) model.MetricDelta {
+	delta := model.MetricDelta{Current: current, Previous: previous, Change: current - previous}
+	if previous != 0 {
+		percent := math.Round(float64(current-previous)*10000/float64(previous)) / 100
+		delta.ChangePercent = &percent
+	}
+	return delta
+}
+
+func (s *reportService) ExportSummary(filter model.ReportFilter, compare string, format string) ([]byte, string, error) {
+	summary, err := s.Summary(filter, compare)
+	if err != nil {
+		return nil, "", err
+	}
+	columns := []export.Column{
+		{Header: "Metric", Kind: export.KindText},
+		{Header: "Value", Kind: export.KindNumber},
+	}
+	if summary.Comparison != nil {
+		columns = append(columns,
+			export.Column{Header: "Previous", Kind: export.KindNumber},
+			export.Column{Header: "Change", Kind: export.KindNumber},
+			export.Column{Header: "Change %", Kind: export.KindPercent},
+		)
+	}
+	table := export.Table{Title: "Sales Summary", Columns: columns}
+	metric := func(name string, value int, delta func(c *model.SummaryComparison) model.MetricDelta, currency bool) {
+		cell := func(v int) interface{} {
+			if currency {
+				return export.Currency(v)
+			}
+			return v
+		}
+		row := []interface{}{name, cell(value)}
+		if summary.Comparison != nil {
+			d := delta(summary.Comparison)
+			var percent interface{}
+			if d.ChangePercent != nil {
+				percent = *d.ChangePercent
+			}
+			row = append(row, cell(d.Previous), cell(d.Change), percent)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	metric("Revenue", summary.TotalRevenue, func(c *model.SummaryComparison) model.MetricDelta { return c.Revenue }, true)
+	metric("Transactions", summary.TotalTransaction, func(c *model.SummaryComparison) model.MetricDelta { return c.TransactionCount }, false)
+	metric("Average Basket", summary.AverageBasket, func(c *model.SummaryComparison) model.MetricDelta { return c.AverageBasket }, true)
+	table.Rows = append(table.Rows,
+		[]interface{}{"Best Seller", summary.ProductTerlaris.Name},
+		[]interface{}{"Best Seller Quantity", summary.ProductTerlaris.QtyTerjual},
+	)
+	return s.export(filter, table, format)
+}
+
+// Transactions lists the transactions of the range, newest first, refunded
+// ones included.
+func (s *reportService) Transactions(filter model.ReportFilter) ([]model.Transaction, error) {
+	return s.transactionRepo.GetAll(filter.From, filter.To, filter.LocationID)
+}
+
+func (s *reportService) ExportTransactions(filter model.ReportFilter, format string) ([]byte, string, error) {
+	transactions, err := s.Transactions(filter)
+	if err != nil {
+		return nil, "", err
+	}
+	table := export.Table{Title: "Transactions", Columns: []export.Column{
+		{Header: "Invoice", Kind: export.KindText},
+		{Header: "Date", Kind: export.KindText},
+		{Header: "Location ID", Kind: export.KindID},
+		{Header: "Customer ID", Kind: export.KindID},
+		{Header: "Items", Kind: export.KindNumber},
+		{Header: "Discount", Kind: export.KindCurrency},
+		{Header: "Tax", Kind: export.KindCurrency},
+		{Header: "Total", Kind: export.KindCurrency},
+		{Header: "Paid", Kind: export.KindCurrency},
+		{Header: "Change", Kind: export.KindCurrency},
+		{Header: "Status", Kind: export.KindText},
+	}}
+	for _, transaction := range transactions {
+		items := 0
+		for _, detail := range transaction.Details {
+			items += detail.Quantity
+		}
+		date := transaction.CreatedAt
+		createdAt, err := time.Parse(time.RFC3339Nano, transaction.CreatedAt)
+		if err == nil {
+			date = createdAt.In(filter.Timezone).Format("2006-01-02 15:04")
+		}
+		status := "completed"
+		if transaction.RefundedAt != nil {
+			status = "refunded"
+		}
+		table.Rows = append(table.Rows, []interface{}{
+			transaction.InvoiceNumber, date, transaction.LocationID, transaction.CustomerID, items,
+			transaction.DiscountAmount, transaction.TaxAmount, transaction.TotalAmount,
+			transaction.PaidAmount, transaction.ChangeAmount, status,
+		})
+	}
+	return s.export(filter, table, format)
+}
+
+// export renders a report table as csv, xlsx or pdf and returns it with its
+// content type.
+func (s *reportService) export(filter model.ReportFilter, table export.Table, format string) ([]byte, string, error) {
+	switch format {
+	case export.FormatCSV:
+		body, err := export.CSV(table)
+		return body, "text/csv; charset=utf-8", err
+	case export.FormatXLSX:
+		body, err := export.XLSX(table)
+		return body, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", err
+	case export.FormatPDF:
+		settings, err := s.receiptRepo.GetSettings()
+		if err != nil {
+			return nil, "", err
+		}
+		lastDay := filter.To.AddDate(0, 0, -1)
+		subtitle := "Periode " + filter.From.Format("02/01/2006") + " - " + lastDay.Format("02/01/2006") + " (" + filter.Timezone.String() + ")"
+		if filter.LocationID != 0 {
+			subtitle += ", lokasi " + strconv.Itoa(filter.LocationID)
+		}
+		body, err := export.PDF(export.Header{
+			StoreName: settings.StoreName,
+			Address:   settings.Address,
+			Phone:     settings.Phone,
+			Title:     table.Title,
+			Subtitle:  subtitle,
+		}, table)
+		return body, "application/pdf", err
+	}
+	return nil, "", export.ErrUnsupportedFormat
+}
+
+func (s *reportService) Timeseries(filter model.ReportFilter, interval string) (*model.TimeseriesResponse, error) {
+	switch interval {
+	case "":
+		interval = model.ReportIntervalDay
+	case model.ReportIntervalHour:
+		if filter.To.After(filter.From.AddDate(0, 0, maxHourlyDays)) {
+			return nil, ErrHourlyRangeTooLong
+		}
+	case model.ReportIntervalDay, model.ReportIntervalWeek, model.ReportIntervalMonth:
+	default:
+		return nil, ErrInvalidInterval
+	}
+
+	points, err := s.reportRepo.Timeseries(filter, interval)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TimeseriesResponse{
+		Interval: interval,
+		Timezone: filter.Timezone.String(),
+		From:     filter.From,
+		To:       filter.To,
+		Points:   points,
+	}, nil
+}
+
+func (s *reportService) TopProducts(filter model.ReportFilter, by string, limit int) (*model.TopResponse, error) {
+	by, limit, err := topParams(by, limit)
+	if err != nil {
+		return nil, err
+	}
+	top, err := s.reportRepo.TopProducts(filter, by, limit)
+	if err != nil {
+		return nil, err
+	}
+	rankTop(top)
+	return top, nil
+}
+
+func (s *reportService) TopCategories(filter model.ReportFilter, by string, limit int) (*model.TopResponse, error) {
+	by, limit, err := topParams(by, limit)
+	if err != nil {
+		return nil, err
+	}
+	top, err := s.reportRepo.TopCategories(filter, by, limit)
+	if err != nil {
+		return nil, err
+	}
+	rankTop(top)
+	return top, nil
+}
+
+func topParams(by string, limit int) (string, int, error) {
+	switch by {
+	case "":
+		by = model.ReportRankByQuantity
+	case model.ReportRankByQuantity, model.ReportRankByRevenue:
+	default:
+		return "", 0, ErrInvalidRankBy
+	}
+	if limit == 0 {
+		limit = defaultTopLimit
+	}
+	if limit < 1 || limit > maxTopLimit {
+		return "", 0, ErrInvalidLimit
+	}
+	return by, limit, nil
+}
+
+// rankTop numbers the items and sets their share of the ranked metric's total
+// as a percentage rounded to two decimals.
+func rankTop(top *model.TopResponse) {
+	total := top.TotalQuantity
+	if top.By == model.ReportRankByRevenue {
+		total = top.TotalRevenue
+	}
+	for i := range top.Items {
+		item := &top.Items[i]
+		item.Rank = i + 1
+		value := item.Quantity
+		if top.By == model.ReportRankByRevenue {
+			value = item.Revenue
+		}
+		if total > 0 {
+			item.Share = math.Round(float64(value)*10000/float64(total)) / 100
+		}
+	}
+}
+
+func (s *reportService) Breakdown(filter model.ReportFilter, group string, sortBy string, order string) (*model.SalesBreakdown, error) {
+	switch group {
+	case "", model.BreakdownGroupCategory, model.BreakdownGroupProduct:
+	default:
+		return nil, ErrInvalidGroup
+	}
+	less, err := breakdownOrder(sortBy, order)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.reportRepo.SalesByProduct(filter)
+	if err != nil {
+		return nil, err
+	}
+	breakdown := model.SalesBreakdown{From: filter.From, To: filter.To, Total: model.BreakdownRow{Name: "Total"}}
+	categories := make([]model.BreakdownRow, 0)
+	categoryIndex := map[int]int{}
+	for i := range products {
+		finishBreakdownRow(&products[i])
+		product := products[i]
+		addBreakdownRow(&breakdown.Total, product)
+		index, ok := categoryIndex[product.CategoryID]
+		if !ok {
+			index = len(categories)
+			categoryIndex[product.CategoryID] = index
+			categories = append(categories, model.BreakdownRow{ID: product.CategoryID, Name: product.CategoryName})
+		}
+		addBreakdownRow(&categories[index], product)
+	}
+	finishBreakdownRow(&breakdown.Total)
+	for i := range categories {
+		finishBreakdownRow(&categories[i])
+	}
+
+	if group != model.BreakdownGroupProduct {
+		sortBreakdown(categories, less)
+		breakdown.Categories = categories
+	}
+	if group != model.BreakdownGroupCategory {
+		sortBreakdown(products, less)
+		breakdown.Products = products
+	}
+	return &breakdown, nil
+}
+
+// ExportBreakdown renders the breakdown of one group as a file. Without a
+// group the products are exported.
+func (s *reportService) ExportBreakdown(filter model.ReportFilter, group string, sortBy string, order string, format string) ([]byte, string, error) {
+	if group == "" {
+		group = model.BreakdownGroupProduct
+	}
+	breakdown, err := s.Breakdown(filter, group, sortBy, order)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.export(filter, breakdownTable(breakdown, group), format)
+}
+
+func breakdownTable(breakdown *model.SalesBreakdown, group string) export.Table {
+	title := "Sales by Product"
+	rows := breakdown.Products
+	columns := []export.Column{
+		{Header: "Product ID", Kind: export.KindID},
+		{Header: "Product", Kind: export.KindText},
+		{Header: "Category", Kind: export.KindText},
+	}
+	if group == model.BreakdownGroupCategory {
+		title = "Sales by Category"
+		rows = breakdown.Categories
+		columns = []export.Column{
+			{Header: "Category ID", Kind: export.KindID},
+			{Header: "Category", Kind: export.KindText},
+		}
+	}
+	columns = append(columns,
+		export.Column{Header: "Quantity", Kind: export.KindNumber},
+		export.Column{Header: "Gross Sales", Kind: export.KindCurrency},
+		export.Column{Header: "Discount", Kind: export.KindCurrency},
+		export.Column{Header: "Net Sales", Kind: export.KindCurrency},
+		export.Column{Header: "Tax", Kind: export.KindCurrency},
+		export.Column{Header: "Cost", Kind: export.KindCurrency},
+		export.Column{Header: "Profit", Kind: export.KindCurrency},
+	)
+
+	table := export.Table{Title: title, Columns: columns}
+	cells := func(id interface{}, row model.BreakdownRow) []interface{} {
+		result := []interface{}{id, row.Name}
+		if group == model.BreakdownGroupProduct {
+			result = append(result, row.CategoryName)
+		}
+		return append(result, row.Quantity, row.GrossSales, row.Discount, row.NetSales, row.Tax, row.Cost, row.Profit)
+	}
+	for _, row := range rows {
+		table.Rows = append(table.Rows, cells(row.ID, row))
+	}
+	table.Rows = append(table.Rows, cells(nil, breakdown.Total))
+	return table
+}
+
+// addBreakdownRow adds the sales of row to total.
+func addBreakdownRow(total *model.BreakdownRow, row model.BreakdownRow) {
+	total.Quantity += row.Quantity
+	total.GrossSales += row.GrossSales
+	total.Discount += row.Discount
+	total.Tax += row.Tax
+	total.CostQuantity += row.CostQuantity
+	total.CostAmount += row.CostAmount
+}
+
+// finishBreakdownRow derives the net sales, and the cost and profit when the
+// whole quantity has a known cost.
+func finishBreakdownRow(row *model.BreakdownRow) {
+	row.NetSales = row.GrossSales - row.Discount
+	row.Cost = nil
+	row.Profit = nil
+	if row.Quantity > 0 && row.CostQuantity == row.Quantity {
+		cost := row.CostAmount
+		profit := row.NetSales - row.Tax - cost
+		row.Cost = &cost
+		row.Profit = &profit
+	}
+}
+
+var breakdownKeys = map[string]func(row model.BreakdownRow) int{
+	"quantity":    func(row model.BreakdownRow) int { return row.Quantity },
+	"gross_sales": func(row model.BreakdownRow) int { return row.GrossSales },
+	"discount":    func(row model.BreakdownRow) int { return row.Discount },
+	"net_sales":   func(row model.BreakdownRow) int { return row.NetSales },
+	"tax":         func(row model.BreakdownRow) int { return row.Tax },
+}
+
+// breakdownOrder returns the row ordering for a sort key, net sales
+// descending by default. Rows without a profit sort last on profit, and ties
+// are broken by name and id.
+func breakdownOrder(sortBy string, order string) (func(a, b model.BreakdownRow) bool, error) {
+	switch order {
+	case "":
+		order = "desc"
+		if sortBy == "name" {
+			order = "asc"
+		}
+	case "asc", "desc":
+	default:
+		return nil, ErrInvalidSortOrder
+	}
+	if sortBy == "" {
+		sortBy = "net_sales"
+	}
+
+	var compare func(a, b model.BreakdownRow) int
+	switch sortBy {
+	case "name":
+		compare = func(a, b model.BreakdownRow) int { return strings.Compare(a.Name, b.Name) }
+	case "profit":
+		compare = func(a, b model.BreakdownRow) int {
+			if a.Profit == nil || b.Profit == nil {
+				return 0
+			}
+			return *a.Profit - *b.Profit
+		}
+	default:
+		key, ok := breakdownKeys[sortBy]
+		if !ok {
+			return nil, ErrInvalidSortKey
+		}
+		compare = func(a, b model.BreakdownRow) int { return key(a) - key(b) }
+	}
+
+	return func(a, b model.BreakdownRow) bool {
+		if sortBy == "profit" && (a.Profit == nil) != (b.Profit == nil) {
+			return b.Profit == nil
+		}
+		if c := compare(a, b); c != 0 {
+			return (c < 0) == (order == "asc")
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	}, nil
+}
+
+func sortBreakdown(rows []model.BreakdownRow, less func(a, b model.BreakdownRow) bool) {
+	sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+}
+
+// Basket lists the product pairs most often bought together in the range.
+func (s *reportService) Basket(filter model.ReportFilter, minTransactions int, limit int) (*model.BasketAnalysis, error) {
+	if minTransactions == 0 {
+		minTransactions = defaultMinPairCount
+	}
+	if minTransactions < 1 {
+		return nil, ErrInvalidMinCount
+	}
+	_, limit, err := topParams("", limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.reportRepo.ProductPairs(filter, minTransactions, limit)
+}
+
+// Related lists the products most often bought together with a product, for
+// cross-selling.
+func (s *reportService) Related(productID int, filter model.ReportFilter, limit int) (*model.RelatedProducts, error) {
+	if limit == 0 {
+		limit = defaultRelatedLimit
+	}
+	_, limit, err := topParams("", limit)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	return s.reportRepo.RelatedProducts(productID, filter, limit)
+}
+
+// Inventory values the stock on hand at cost and at selling price. The filter
+// holds the whole days whose sales give the average daily sales behind the
+// days of cover, ending with today; stock that has not sold in the last
+// deadDays days, today included, is listed as dead stock.
+func (s *reportService) Inventory(filter model.ReportFilter, deadDays int, group string) (*model.InventoryReport, error) {
+	switch group {
+	case "", model.BreakdownGroupCategory, model.BreakdownGroupProduct:
+	default:
+		return nil, ErrInvalidGroup
+	}
+	from := filter.From.In(filter.Timezone)
+	to := filter.To.In(filter.Timezone)
+	salesDays := int(math.Round(to.Sub(from).Hours() / 24))
+	deadFrom := to.AddDate(0, 0, -deadDays).Format(period.DateLayout)
+
+	products, err := s.reportRepo.Inventory(filter.LocationID, from.Format(period.DateLayout), to.Format(period.DateLayout))
+	if err != nil {
+		return nil, err
+	}
+	report := model.InventoryReport{
+		Date:          to.AddDate(0, 0, -1).Format(period.DateLayout),
+		LocationID:    filter.LocationID,
+		SalesDays:     salesDays,
+		DeadStockDays: deadDays,
+		Total:         model.InventoryRow{Name: "Total"},
+		DeadStock:     make([]model.InventoryRow, 0),
+	}
+	categories := make([]model.InventoryRow, 0)
+	categoryIndex := map[int]int{}
+	for i := range products {
+		products[i].RetailValue = products[i].Quantity * products[i].Price
+		finishInventoryRow(&products[i], salesDays)
+		product := products[i]
+		addInventoryRow(&report.Total, product)
+		index, ok := categoryIndex[product.CategoryID]
+		if !ok {
+			index = len(categories)
+			categoryIndex[product.CategoryID] = index
+			categories = append(categories, model.InventoryRow{ID: product.CategoryID, Name: product.CategoryName})
+		}
+		addInventoryRow(&categories[index], product)
+		if product.Quantity > 0 && product.LastSoldDate < deadFrom {
+			report.DeadStock = append(report.DeadStock, product)
+		}
+	}
+	finishInventoryRow(&report.Total, salesDays)
+	for i := range categories {
+		finishInventoryRow(&categories[i], salesDays)
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	// The dead stock tying up the most money comes first
+	sort.SliceStable(report.DeadStock, func(i, j int) bool {
+		return report.DeadStock[i].RetailValue > report.DeadStock[j].RetailValue
+	})
+
+	if group != model.BreakdownGroupProduct {
+		report.Categories = categories
+	}
+	if group != model.BreakdownGroupCategory {
+		report.Products = products
+	}
+	return &report, nil
+}
+
+func addInventoryRow(total *model.InventoryRow, row model.InventoryRow) {
+	total.Quantity += row.Quantity
+	total.RetailValue += row.RetailValue
+	total.SoldQuantity += row.SoldQuantity
+	total.CostQuantity += row.CostQuantity
+	total.CostAmount += row.CostAmount
+}
+
+// finishInventoryRow fills in the cost value and the days of cover. Days of
+// cover are left out when nothing was sold, as the stock would never run out
+// at that rate.
+func finishInventoryRow(row *model.InventoryRow, salesDays int) {
+	row.CostValue = nil
+	if row.CostQuantity == row.Quantity {
+		cost := row.CostAmount
+		row.CostValue = &cost
+	}
+	row.AverageDailySales = 0
+	row.DaysOfCover = nil
+	if row.SoldQuantity > 0 && salesDays > 0 {
+		average := float64(row.SoldQuantity) / float64(salesDays)
+		cover := 0.0
+		if row.Quantity > 0 {
+			cover = math.Round(float64(row.Quantity)/average*10) / 10
+		}
+		row.AverageDailySales = math.Round(average*100) / 100
+		row.DaysOfCover = &cover
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"product-api/model"
+	"product-api/repository"
+	"strings"
+	"time"
+)
+
+type ReservationServiceInterface interface {
+	Create(request *model.CreateReservationRequest) (*model.Reservation, error)
+	GetByReference(reference string) (*model.Reservation, error)
+	Release(reference string) error
+	ReleaseExpired() (int64, error)
+	RunSweeper(interval time.Duration)
+}
+
+type reservationService struct {
+	reservationRepo repository.ReservationRepositoryInterface
+	productRepo     repository.ProductRepositoryInterface
+	defaultTTL      time.Duration
+}
+
+func NewReservationService(reservationRepo repository.ReservationRepositoryInterface, productRepo repository.ProductRepositoryInterface, defaultTTL time.Duration) ReservationServiceInterface {
+	return &reservationService{
+		reservationRepo: reservationRepo,
+		productRepo:     productRepo,
+		defaultTTL:      defaultTTL,
+	}
+}
+
+// Create reserves every item for the order reference. The reservation holds
+// as long as all items are available; the stock itself is only deducted when
+// the order is paid through checkout.
+func (s *reservationService) Create(request *model.CreateReservationRequest) (*model.Reservation, error) {
+	reference := strings.TrimSpace(request.Reference)
+	if reference == "" {
+		return nil, errors.New("reference is required")
+	}
+	if len(request.Items) == 0 {
+		return nil, errors.New("items are required")
+	}
+	if request.TTLMinutes < 0 {
+		return nil, errors.New("ttl minutes must not be negative")
+	}
+	ttl := s.defaultTTL
+	if request.TTLMinutes > 0 {
+		ttl = time.Duration(request.TTLMinutes) * time.Minute
+	}
+
+	// Merge repeated products into a single line
+	var productIDs []int
+	quantities := map[int]int{}
+	for _, item := range request.Items {
+		if item.Quantity <= 0 {
+			return nil, errors.New("quantity must be greater than zero")
+		}
+		if _, ok := quantities[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	tx, err := s.reservationRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(ttl)
+	for _, productID := range productIDs {
+		product, err := s.productRepo.LockByID(tx, productID)
+		if err != nil {
+			s.reservationRepo.RollbackTrans(tx)
+			return nil, err
+		}
+		reserved, err := s.reservationRepo.GetReserved(tx, productID, 0, "")
+		if err != nil {
+			s.reservationRepo.RollbackTrans(tx)
+			return nil, err
+		}
+		if product.Stock-reserved < quantities[productID] {
+			s.reservationRepo.RollbackTrans(tx)
+			return nil, errors.New("product stock not enough")
+		}
+		err = s.reservationRepo.CreateOrder(tx, reference, productID, quantities[productID], expiresAt)
+		if err != nil {
+			s.reservationRepo.RollbackTrans(tx)
+			return nil, err
+		}
+	}
+	err = s.reservationRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	return s.reservationRepo.GetByReference(reference)
+}
+
+func (s *reservationService) GetByReference(reference string) (*model.Reservation, error) {
+	return s.reservationRepo.GetByReference(reference)
+}
+
+func (s *reservationService) Release(reference string) error {
+	tx, err := s.reservationRepo.BeginTrans()
+	if err != nil {
+		return err
+	}
+	err = s.reservationRepo.ReleaseReference(tx, reference)
+	if err != nil {
+		s.reservationRepo.RollbackTrans(tx)
+		return err
+	}
+	return s.reservationRepo.CommitTrans(tx)
+}
+
+func (s *reservationService) ReleaseExpired() (int64, error) {
+	return s.reservationRepo.DeleteExpired()
+}
+
+// RunSweeper releases expired reservations every interval. It blocks, so run
+// it in its own goroutine.
+func (s *reservationService) RunSweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		released, err := s.ReleaseExpired()
+		if err != nil {
+			log.Printf("Failed to release expired reservations: %v", err)
+			continue
+		}
+		if released > 0 {
+			log.Printf("Released %d expired reservations", released)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"log"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/utils/period"
+	"sync"
+	"time"
+)
+
+// Sizes of the queue of sales waiting to be broadcast and of the buffer of
+// every subscriber. A subscriber whose buffer is full is dropped, so one slow
+// client cannot hold up the others; it reconnects and starts from a fresh
+// snapshot.
+const (
+	salesFeedQueueSize  = 256
+	salesFeedBufferSize = 32
+)
+
+type SalesFeedInterface interface {
+	Publish(eventType string, transaction model.Transaction)
+	Subscribe(locationID int) (<-chan model.SalesEvent, func())
+	Snapshot(locationID int) (model.SalesEvent, error)
+	Run()
+}
+
+type salesSubscriber struct {
+	locationID int
+	events     chan model.SalesEvent
+}
+
+type salesFeed struct {
+	reportRepo  repository.ReportRepositoryInterface
+	timezone    *time.Location
+	queue       chan model.SalesEvent
+	mu          sync.Mutex
+	subscribers map[*salesSubscriber]struct{}
+	lastID      int64
+}
+
+// NewSalesFeed creates the in-process broadcaster of the live sales feed. The
+// running totals are those of the current day in timezone.
+func NewSalesFeed(reportRepo repository.ReportRepositoryInterface, timezone *time.Location) SalesFeedInterface {
+	return &salesFeed{
+		reportRepo:  reportRepo,
+		timezone:    timezone,
+		queue:       make(chan model.SalesEvent, salesFeedQueueSize),
+		subscribers: map[*salesSubscriber]struct{}{},
+	}
+}
+
+// Publish queues a committed checkout or refund for broadcasting. It never
+// blocks the sale; when the queue is full the event is dropped.
+func (s *salesFeed) Publish(eventType string, transaction model.Transaction) {
+	select {
+	case s.queue <- model.SalesEvent{Type: eventType, Transaction: &transaction, CreatedAt: time.Now()}:
+	default:
+		log.Printf("Sales feed queue full, dropped %s event of transaction %d", eventType, transaction.ID)
+	}
+}
+
+// Subscribe registers a client for the events of one location, or of all
+// locations when locationID is zero. The returned function unsubscribes; the
+// channel is also closed when the client falls behind.
+func (s *salesFeed) Subscribe(locationID int) (<-chan model.SalesEvent, func()) {
+	subscriber := &salesSubscriber{locationID: locationID, events: make(chan model.SalesEvent, salesFeedBufferSize)}
+	s.mu.Lock()
+	s.subscribers[subscriber] = struct{}{}
+	s.mu.Unlock()
+	return subscriber.events, func() { s.remove(subscriber) }
+}
+
+// Snapshot returns a totals event with the running totals of today.
+func (s *salesFeed) Snapshot(locationID int) (model.SalesEvent, error) {
+	totals, err := s.today(locationID)
+	if err != nil {
+		return model.SalesEvent{}, err
+	}
+	return model.SalesEvent{Type: model.SalesEventTotals, Today: totals, CreatedAt: time.Now()}, nil
+}
+
+// Run broadcasts the published events in order. It blocks, so run it in its
+// own goroutine.
+func (s *salesFeed) Run() {
+	for event := range s.queue {
+		s.broadcast(event)
+	}
+}
+
+func (s *salesFeed) broadcast(event model.SalesEvent) {
+	locationID := 0
+	if event.Transaction.LocationID != nil {
+		locationID = *event.Transaction.LocationID
+	}
+
+	s.mu.Lock()
+	var subscribers []*salesSubscriber
+	for subscriber := range s.subscribers {
+		if subscriber.locationID == 0 || subscriber.locationID == locationID {
+			subscribers = append(subscribers, subscriber)
+		}
+	}
+	s.mu.Unlock()
+	if len(subscribers) == 0 {
+		return
+	}
+
+	// Totals are computed once per scope, not once per client
+	totals := map[int]model.SalesTotals{}
+	for _, subscriber := range subscribers {
+		if _, ok := totals[subscriber.locationID]; ok {
+			continue
+		}
+		today, err := s.today(subscriber.locationID)
+		if err != nil {
+			log.Printf("Failed to compute sales feed totals: %v", err)
+		}
+		totals[subscriber.locationID] = today
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	event.ID = s.lastID
+	for _, subscriber := range subscribers {
+		if _, ok := s.subscribers[subscriber]; !ok {
+			continue
+		}
+		event.Today = totals[subscriber.locationID]
+		select {
+		case subscriber.events <- event:
+		default:
+			delete(s.subscribers, subscriber)
+			close(subscriber.events)
+		}
+	}
+}
+
+func (s *salesFeed) remove(subscriber *salesSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[subscriber]; ok {
+		delete(s.subscribers, subscriber)
+		close(subscriber.events)
+	}
+}
+
+func (s *salesFeed) today(locationID int) (model.SalesTotals, error) {
+	from, to := period.Day(time.Now(), s.timezone)
+	totals := model.SalesTotals{Date: from.Format(period.DateLayout), LocationID: locationID}
+	summary, err := s.reportRepo.Summary(model.ReportFilter{From: from, To: to, Timezone: s.timezone, LocationID: locationID})
+	if err != nil {
+		return totals, err
+	}
+	totals.Revenue = summary.TotalRevenue
+	totals.TransactionCount = summary.TotalTransaction
+	if summary.TotalTransaction > 0 {
+		totals.AverageBasket = summary.TotalRevenue / summary.TotalTransaction
+	}
+	return totals, nil
+}
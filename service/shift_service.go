@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"strings"
+)
+
+type ShiftServiceInterface interface {
+	Open(shift *model.Shift) error
+	GetAll(cashier string, status string) ([]model.Shift, error)
+	GetByID(id int) (*model.Shift, error)
+	AddCashEvent(event *model.CashEvent) error
+	Close(id int, request *model.CloseShiftRequest) (model.ShiftReport, error)
+	Report(id int) (model.ShiftReport, error)
+}
+
+type shiftService struct {
+	shiftRepo       repository.ShiftRepositoryInterface
+	locationRepo    repository.LocationRepositoryInterface
+	defaultLocation string
+}
+
+// NewShiftService creates the shift service. Shifts opened without a
+// location are opened at the defaultLocation code.
+func NewShiftService(shiftRepo repository.ShiftRepositoryInterface, locationRepo repository.LocationRepositoryInterface, defaultLocation string) ShiftServiceInterface {
+	return &shiftService{shiftRepo: shiftRepo, locationRepo: locationRepo, defaultLocation: defaultLocation}
+}
+
+func (s *shiftService) Open(shift *model.Shift) error {
+	shift.Cashier = strings.TrimSpace(shift.Cashier)
+	if shift.Cashier == "" {
+		return errors.New("cashier is required")
+	}
+	if shift.OpeningFloat < 0 {
+		return errors.New("opening float must not be negative")
+	}
+	if shift.LocationID == 0 {
+		location, err := s.locationRepo.GetByCode(s.defaultLocation)
+		if err != nil {
+			return err
+		}
+		shift.LocationID = location.ID
+	} else {
+		_, err := s.locationRepo.GetByID(shift.LocationID)
+		if err != nil {
+			return err
+		}
+	}
+	return s.shiftRepo.Open(shift)
+}
+
+func (s *shiftService) GetAll(cashier string, status string) ([]model.Shift, error) {
+	var open *bool
+	switch status {
+	case "":
+	case "open", "closed":
+		isOpen := status == "open"
+		open = &isOpen
+	default:
+		return nil, errors.New("status must be open or closed")
+	}
+	return s.shiftRepo.GetAll(cashier, open)
+}
+
+func (s *shiftService) GetByID(id int) (*model.Shift, error) {
+	return s.shiftRepo.GetByID(id)
+}
+
+func (s *shiftService) AddCashEvent(event *model.CashEvent) error {
+	if event.Type != model.CashEventIn && event.Type != model.CashEventOut {
+		return errors.New("cash event type must be in or out")
+	}
+	if event.Amount <= 0 {
+		return errors.New("amount must be greater than zero")
+	}
+	_, err := s.shiftRepo.GetByID(event.ShiftID)
+	if err != nil {
+		return err
+	}
+	return s.shiftRepo.AddCashEvent(event)
+}
+
+func (s *shiftService) Close(id int, request *model.CloseShiftRequest) (model.ShiftReport, error) {
+	if request.CountedCash < 0 {
+		return model.ShiftReport{}, errors.New("counted cash must not be negative")
+	}
+
+	tx, err := s.shiftRepo.BeginTrans()
+	if err != nil {
+		return model.ShiftReport{}, err
+	}
+	shift, err := s.shiftRepo.LockOpen(tx, id)
+	if err != nil {
+		s.shiftRepo.RollbackTrans(tx)
+		return model.ShiftReport{}, err
+	}
+	report, err := s.buildReport(shift)
+	if err != nil {
+		s.shiftRepo.RollbackTrans(tx)
+		return model.ShiftReport{}, err
+	}
+
+	shift.ExpectedCash = &report.ExpectedCash
+	shift.CountedCash = &request.CountedCash
+	if request.Note != "" {
+		shift.Note = request.Note
+	}
+	err = s.shiftRepo.Close(tx, shift)
+	if err != nil {
+		s.shiftRepo.RollbackTrans(tx)
+		return model.ShiftReport{}, err
+	}
+	err = s.shiftRepo.CommitTrans(tx)
+	if err != nil {
+		return model.ShiftReport{}, err
+	}
+
+	report.Shift = *shift
+	report.CountedCash = shift.CountedCash
+	overShort := request.CountedCash - report.ExpectedCash
+	report.OverShort = &overShort
+	return report, nil
+}
+
+func (s *shiftService) Report(id int) (model.ShiftReport, error) {
+	shift, err := s.shiftRepo.GetByID(id)
+	if err != nil {
+		return model.ShiftReport{}, err
+	}
+	report, err := s.buildReport(shift)
+	if err != nil {
+		return model.ShiftReport{}, err
+	}
+
+	// A closed shift keeps the expected cash it was closed with
+	if shift.ClosedAt != nil && shift.ExpectedCash != nil && shift.CountedCash != nil {
+		report.ExpectedCash = *shift.ExpectedCash
+		report.CountedCash = shift.CountedCash
+		overShort := *shift.CountedCash - *shift.ExpectedCash
+		report.OverShort = &overShort
+	}
+	return report, nil
+}
+
+// buildReport computes the expected drawer cash: opening float plus cash
+// sales net of change, minus cash refunded, plus cash in, minus cash out.
+func (s *shiftService) buildReport(shift *model.Shift) (model.ShiftReport, error) {
+	sales, err := s.shiftRepo.GetSales(shift.ID)
+	if err != nil {
+		return model.ShiftReport{}, err
+	}
+	refunds, err := s.shiftRepo.GetRefunds(shift.ID)
+	if err != nil {
+		return model.ShiftReport{}, err
+	}
+	events, err := s.shiftRepo.GetCashEvents(shift.ID)
+	if err != nil {
+		return model.ShiftReport{}, err
+	}
+
+	report := model.ShiftReport{Shift: *shift, Sales: sales, Refunds: refunds, CashEvents: events}
+	report.CashSales = cashTotal(sales)
+	report.CashRefunds = cashTotal(refunds)
+	for _, event := range events {
+		if event.Type == model.CashEventIn {
+			report.CashIn += event.Amount
+		} else {
+			report.CashOut += event.Amount
+		}
+	}
+	report.ExpectedCash = shift.OpeningFloat + report.CashSales - report.CashRefunds + report.CashIn - report.CashOut
+	return report, nil
+}
+
+// cashTotal returns the cash that went through the drawer, net of change.
+func cashTotal(sales model.ShiftSales) int {
+	total := -sales.ChangeGiven
+	for _, payment := range sales.Payments {
+		if payment.Method == model.PaymentMethodCash {
+			total += payment.Amount
+		}
+	}
+	return total
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"strings"
+)
+
+type StocktakeServiceInterface interface {
+	Start(stocktake *model.Stocktake) error
+	GetAll(status string, locationID int) ([]model.Stocktake, error)
+	GetByID(id int) (*model.Stocktake, error)
+	AddCounts(id int, request *model.StocktakeCountRequest) (model.StocktakeVarianceReport, error)
+	Variance(id int) (model.StocktakeVarianceReport, error)
+	Approve(id int, request *model.ApproveStocktakeRequest) (model.StocktakeVarianceReport, error)
+	Cancel(id int) (*model.Stocktake, error)
+}
+
+type stocktakeService struct {
+	stocktakeRepo   repository.StocktakeRepositoryInterface
+	stockRepo       repository.StockRepositoryInterface
+	productRepo     repository.ProductRepositoryInterface
+	locationRepo    repository.LocationRepositoryInterface
+	defaultLocation string
+}
+
+func NewStocktakeService(stocktakeRepo repository.StocktakeRepositoryInterface, stockRepo repository.StockRepositoryInterface, productRepo repository.ProductRepositoryInterface, locationRepo repository.LocationRepositoryInterface, defaultLocation string) StocktakeServiceInterface {
+	return &stocktakeService{
+		stocktakeRepo:   stocktakeRepo,
+		stockRepo:       stockRepo,
+		productRepo:     productRepo,
+		locationRepo:    locationRepo,
+		defaultLocation: defaultLocation,
+	}
+}
+
+func (s *stocktakeService) Start(stocktake *model.Stocktake) error {
+	if stocktake.LocationID == 0 {
+		location, err := s.locationRepo.GetByCode(s.defaultLocation)
+		if err != nil {
+			return err
+		}
+		stocktake.LocationID = location.ID
+	} else {
+		_, err := s.locationRepo.GetByID(stocktake.LocationID)
+		if err != nil {
+			return err
+		}
+	}
+	stocktake.Status = model.StocktakeStatusCounting
+
+	tx, err := s.stocktakeRepo.BeginTrans()
+	if err != nil {
+		return err
+	}
+	err = s.stocktakeRepo.Start(tx, stocktake)
+	if err != nil {
+		s.stocktakeRepo.RollbackTrans(tx)
+		return err
+	}
+	return s.stocktakeRepo.CommitTrans(tx)
+}
+
+func (s *stocktakeService) GetAll(status string, locationID int) ([]model.Stocktake, error) {
+	switch status {
+	case "", model.StocktakeStatusCounting, model.StocktakeStatusApproved, model.StocktakeStatusCancelled:
+	default:
+		return nil, errors.New("status must be counting, approved or cancelled")
+	}
+	return s.stocktakeRepo.GetAll(status, locationID)
+}
+
+func (s *stocktakeService) GetByID(id int) (*model.Stocktake, error) {
+	return s.stocktakeRepo.GetByID(id)
+}
+
+// AddCounts records counted quantities. Counts for the same product, e.g.
+// from several devices or shelves, add up. Each count also stores the stock
+// the system held at that moment, so sales made while counting are compared
+// against the right figure instead of the snapshot.
+func (s *stocktakeService) AddCounts(id int, request *model.StocktakeCountRequest) (model.StocktakeVarianceReport, error) {
+	if len(request.Counts) == 0 {
+		return model.StocktakeVarianceReport{}, errors.New("counts are required")
+	}
+	for _, count := range request.Counts {
+		if count.Quantity < 0 {
+			return model.StocktakeVarianceReport{}, errors.New("quantity must not be negative")
+		}
+	}
+	device := strings.TrimSpace(request.Device)
+
+	tx, err := s.stocktakeRepo.BeginTrans()
+	if err != nil {
+		return model.StocktakeVarianceReport{}, err
+	}
+	stocktake, err := s.stocktakeRepo.LockCounting(tx, id)
+	if err != nil {
+		s.stocktakeRepo.RollbackTrans(tx)
+		return model.StocktakeVarianceReport{}, err
+	}
+	for _, count := range request.Counts {
+		_, err = s.productRepo.LockByID(tx, count.ProductID)
+		if err != nil {
+			s.stocktakeRepo.RollbackTrans(tx)
+			return model.StocktakeVarianceReport{}, err
+		}
+		systemQuantity, err := s.stockRepo.LockLevel(tx, stocktake.LocationID, count.ProductID)
+		if err != nil {
+			s.stocktakeRepo.RollbackTrans(tx)
+			return model.StocktakeVarianceReport{}, err
+		}
+		err = s.stocktakeRepo.AddCount(tx, id, count.ProductID, count.Quantity, systemQuantity, device)
+		if err != nil {
+			s.stocktakeRepo.RollbackTrans(tx)
+			return model.StocktakeVarianceReport{}, err
+		}
+	}
+	err = s.stocktakeRepo.CommitTrans(tx)
+	if err != nil {
+		return model.StocktakeVarianceReport{}, err
+	}
+	return s.Variance(id)
+}
+
+func (s *stocktakeService) Variance(id int) (model.StocktakeVarianceReport, error) {
+	stocktake, err := s.stocktakeRepo.GetByID(id)
+	if err != nil {
+		return model.StocktakeVarianceReport{}, err
+	}
+	items, err := s.stocktakeRepo.GetItems(id)
+	if err != nil {
+		return model.StocktakeVarianceReport{}, err
+	}
+	return buildVarianceReport(*stocktake, items), nil
+}
+
+// Approve posts the variance of every counted product as a stock adjustment
+// at the location. The variance is taken against the stock at count time and
+// applied to the current stock, so movements after the count are kept.
+// Uncounted products are left alone unless ZeroUncounted is set.
+func (s *stocktakeService) Approve(id int, request *model.ApproveStocktakeRequest) (model.StocktakeVarianceReport, error) {
+	tx, err := s.stocktakeRepo.BeginTrans()
+	if err != nil {
+		return model.StocktakeVarianceReport{}, err
+	}
+	stocktake, err := s.stocktakeRepo.LockCounting(tx, id)
+	if err != nil {
+		s.stocktakeRepo.RollbackTrans(tx)
+		return model.StocktakeVarianceReport{}, err
+	}
+	items, err := s.stocktakeRepo.GetItems(id)
+	if err != nil {
+		s.stocktakeRepo.RollbackTrans(tx)
+		return model.StocktakeVarianceReport{}, err
+	}
+
+	for _, item := range items {
+		if item.Counted == nil && !request.ZeroUncounted {
+			continue
+		}
+		_, err = s.productRepo.LockByID(tx, item.ProductID)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			// Deleted while counting
+			continue
+		}
+		if err != nil {
+			s.stocktakeRepo.RollbackTrans(tx)
+			return model.StocktakeVarianceReport{}, err
+		}
+		if item.Counted == nil {
+			systemQuantity, err := s.stockRepo.LockLevel(tx, stocktake.LocationID, item.ProductID)
+			if err != nil {
+				s.stocktakeRepo.RollbackTrans(tx)
+				return model.StocktakeVarianceReport{}, err
+			}
+			zero := 0
+			item.Counted = &zero
+			item.SystemQuantity = &systemQuantity
+		}
+
+		adjustment := *item.Counted - *item.SystemQuantity
+		item.Adjustment = &adjustment
+		if adjustment != 0 {
+			err = s.stockRepo.Adjust(tx, stocktake.LocationID, item.ProductID, adjustment)
+			if err != nil {
+				s.stocktakeRepo.RollbackTrans(tx)
+				return model.StocktakeVarianceReport{}, err
+			}
+		}
+		if adjustment < 0 {
+			err = s.stockRepo.TrimBatches(tx, stocktake.LocationID, item.ProductID)
+			if err != nil {
+				s.stocktakeRepo.RollbackTrans(tx)
+				return model.StocktakeVarianceReport{}, err
+			}
+		}
+		err = s.stocktakeRepo.SetAdjustment(tx, id, item)
+		if err != nil {
+			s.stocktakeRepo.RollbackTrans(tx)
+			return model.StocktakeVarianceReport{}, err
+		}
+	}
+
+	stocktake.Status = model.StocktakeStatusApproved
+	err = s.stocktakeRepo.Complete(tx, stocktake)
+	if err != nil {
+		s.stocktakeRepo.RollbackTrans(tx)
+		return model.StocktakeVarianceReport{}, err
+	}
+	err = s.stocktakeRepo.CommitTrans(tx)
+	if err != nil {
+		return model.StocktakeVarianceReport{}, err
+	}
+	return s.Variance(id)
+}
+
+func (s *stocktakeService) Cancel(id int) (*model.Stocktake, error) {
+	tx, err := s.stocktakeRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	stocktake, err := s.stocktakeRepo.LockCounting(tx, id)
+	if err != nil {
+		s.stocktakeRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	stocktake.Status = model.StocktakeStatusCancelled
+	err = s.stocktakeRepo.Complete(tx, stocktake)
+	if err != nil {
+		s.stocktakeRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.stocktakeRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	return stocktake, nil
+}
+
+func buildVarianceReport(stocktake model.Stocktake, items []model.StocktakeItem) model.StocktakeVarianceReport {
+	report := model.StocktakeVarianceReport{
+		Stocktake:  stocktake,
+		TotalItems: len(items),
+		Rows:       make([]model.StocktakeVarianceRow, 0),
+	}
+	for _, item := range items {
+		if item.Counted == nil || item.SystemQuantity == nil {
+			report.UncountedItems++
+			continue
+		}
+		report.CountedItems++
+		row := model.StocktakeVarianceRow{
+			ProductID:      item.ProductID,
+			ProductName:    item.ProductName,
+			Expected:       item.Expected,
+			SystemQuantity: *item.SystemQuantity,
+			Counted:        *item.Counted,
+			Variance:       *item.Counted - *item.SystemQuantity,
+		}
+		row.VarianceValue = row.Variance * item.Price
+		report.TotalVariance += row.Variance
+		report.VarianceValue += row.VarianceValue
+		report.Rows = append(report.Rows, row)
+	}
+	return report
+}
@@ -1,91 +1,355 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
 	"product-api/model"
 	"product-api/repository"
+	"product-api/utils/invoice"
+	"product-api/utils/period"
+	"strings"
+	"time"
 )
 
 type TransactionServiceInterface interface {
 	Checkout(checkoutRequest *model.CheckoutRequest) (model.Transaction, error)
-	Summary(fromDate string, toDate string) (model.SummaryResponse, error)
+	CheckoutTx(tx *sql.Tx, checkoutRequest *model.CheckoutRequest) (model.Transaction, error)
+	Refund(id int, request *model.RefundRequest) (*model.Transaction, error)
+	GetByID(id int) (*model.Transaction, error)
+	GetByInvoiceNumber(invoiceNumber string) (*model.Transaction, error)
 }
 
 type transactionService struct {
 	transactionRepo repository.TransactionRepositoryInterface
 	productRepo     repository.ProductRepositoryInterface
+	customerRepo    repository.CustomerRepositoryInterface
+	invoiceRepo     repository.InvoiceRepositoryInterface
+	shiftRepo       repository.ShiftRepositoryInterface
+	reservationRepo repository.ReservationRepositoryInterface
+	stockRepo       repository.StockRepositoryInterface
+	locationRepo    repository.LocationRepositoryInterface
+	loyaltyService  LoyaltyServiceInterface
+	batchService    BatchServiceInterface
+	rollupRepo      repository.RollupRepositoryInterface
+	zReportRepo     repository.ZReportRepositoryInterface
+	salesFeed       SalesFeedInterface
+	checkoutConfig  model.CheckoutConfig
 }
 
-func NewTransactionService(transactionRepo repository.TransactionRepositoryInterface, productRepo repository.ProductRepositoryInterface) TransactionServiceInterface {
+func NewTransactionService(transactionRepo repository.TransactionRepositoryInterface, productRepo repository.ProductRepositoryInterface, customerRepo repository.CustomerRepositoryInterface, invoiceRepo repository.InvoiceRepositoryInterface, shiftRepo repository.ShiftRepositoryInterface, reservationRepo repository.ReservationRepositoryInterface, stockRepo repository.StockRepositoryInterface, locationRepo repository.LocationRepositoryInterface, loyaltyService LoyaltyServiceInterface, batchService BatchServiceInterface, rollupRepo repository.RollupRepositoryInterface, zReportRepo repository.ZReportRepositoryInterface, salesFeed SalesFeedInterface, checkoutConfig model.CheckoutConfig) TransactionServiceInterface {
 	return &transactionService{
 		transactionRepo: transactionRepo,
 		productRepo:     productRepo,
+		customerRepo:    customerRepo,
+		invoiceRepo:     invoiceRepo,
+		shiftRepo:       shiftRepo,
+		reservationRepo: reservationRepo,
+		stockRepo:       stockRepo,
+		locationRepo:    locationRepo,
+		loyaltyService:  loyaltyService,
+		batchService:    batchService,
+		rollupRepo:      rollupRepo,
+		zReportRepo:     zReportRepo,
+		salesFeed:       salesFeed,
+		checkoutConfig:  checkoutConfig,
 	}
 }
 
 func (s *transactionService) Checkout(checkoutRequest *model.CheckoutRequest) (model.Transaction, error) {
-	tx, _ := s.productRepo.BeginTrans()
-	transaction := model.Transaction{}
-	for _, item := range checkoutRequest.Items {
-		product, err := s.productRepo.GetByID(item.ProductID)
+	tx, err := s.productRepo.BeginTrans()
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	transaction, err := s.CheckoutTx(tx, checkoutRequest)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return model.Transaction{}, err
+	}
+	err = s.productRepo.CommitTrans(tx)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	s.salesFeed.Publish(model.SalesEventCheckout, transaction)
+	return transaction, nil
+}
+
+// CheckoutTx writes the checkout into tx without committing it, so callers
+// such as carts can finish their own bookkeeping atomically with the sale.
+// They publish the sale on the sales feed once committed.
+// Stock reserved by others is not sellable; the reservation of the order or
+// cart being checked out is consumed.
+func (s *transactionService) CheckoutTx(tx *sql.Tx, checkoutRequest *model.CheckoutRequest) (model.Transaction, error) {
+	if checkoutRequest.RedeemPoints < 0 {
+		return model.Transaction{}, errors.New("redeem points must not be negative")
+	}
+	if checkoutRequest.RedeemPoints > 0 && checkoutRequest.CustomerID == nil {
+		return model.Transaction{}, errors.New("customer is required to redeem points")
+	}
+	if checkoutRequest.CustomerID != nil {
+		_, err := s.customerRepo.GetByID(*checkoutRequest.CustomerID)
 		if err != nil {
-			s.productRepo.RollbackTrans(tx)
 			return model.Transaction{}, err
 		}
-		if product.Stock < item.Quantity {
-			s.productRepo.RollbackTrans(tx)
+	}
+
+	if strings.TrimSpace(checkoutRequest.Cashier) == "" {
+		return model.Transaction{}, errors.New("cashier is required")
+	}
+
+	shift, err := s.shiftRepo.GetOpenByCashier(tx, strings.TrimSpace(checkoutRequest.Cashier))
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	// The business day follows the store's calendar, not the server's, and
+	// must not have been closed with a Z-report yet
+	now := time.Now().In(s.checkoutConfig.Timezone)
+	err = s.zReportRepo.CheckOpen(tx, shift.LocationID, now.Format(period.DateLayout))
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	items := checkoutRequest.Items
+	if checkoutRequest.Reservation != "" {
+		reservation, err := s.reservationRepo.LockByReference(tx, checkoutRequest.Reservation)
+		if err != nil {
+			return model.Transaction{}, err
+		}
+		if len(items) == 0 {
+			for _, item := range reservation.Items {
+				items = append(items, model.CheckoutItem{ProductID: item.ProductID, Quantity: item.Quantity})
+			}
+		}
+	}
+
+	transaction := model.Transaction{CustomerID: checkoutRequest.CustomerID, ShiftID: &shift.ID, LocationID: &shift.LocationID}
+	for _, item := range items {
+		product, err := s.productRepo.LockByID(tx, item.ProductID)
+		if err != nil {
+			return model.Transaction{}, err
+		}
+		reserved, err := s.reservationRepo.GetReserved(tx, product.ID, checkoutRequest.CartID, checkoutRequest.Reservation)
+		if err != nil {
+			return model.Transaction{}, err
+		}
+		if product.Stock-reserved < item.Quantity {
 			return model.Transaction{}, errors.New("product stock not enough")
 		}
-		product.Stock -= item.Quantity
-		err = s.productRepo.Update(tx, product)
+		batches, err := s.batchService.Allocate(tx, product.ID, shift.LocationID, item.Quantity)
+		if err != nil {
+			return model.Transaction{}, err
+		}
+		err = s.stockRepo.Adjust(tx, shift.LocationID, product.ID, -item.Quantity)
 		if err != nil {
-			s.productRepo.RollbackTrans(tx)
 			return model.Transaction{}, err
 		}
 		transactionDetails := model.TransactionDetail{
 			ProductID:   product.ID,
 			Quantity:    item.Quantity,
 			ProductName: product.Name,
+			Price:       product.Price,
 			Subtotal:    product.Price * item.Quantity,
+			Batches:     batches,
 		}
 		transaction.Details = append(transaction.Details, transactionDetails)
 		transaction.TotalAmount += transactionDetails.Subtotal
 	}
 
-	err := s.transactionRepo.Create(tx, &transaction)
+	if checkoutRequest.RedeemPoints > 0 {
+		transaction.DiscountAmount = s.loyaltyService.RedeemValue(checkoutRequest.RedeemPoints)
+		if transaction.DiscountAmount > transaction.TotalAmount {
+			return model.Transaction{}, errors.New("redeemed points exceed transaction total")
+		}
+		transaction.TotalAmount -= transaction.DiscountAmount
+	}
+
+	transaction.TaxAmount = s.includedTax(transaction.TotalAmount)
+	err = settlePayments(&transaction, checkoutRequest.Payments)
 	if err != nil {
-		s.productRepo.RollbackTrans(tx)
 		return model.Transaction{}, err
 	}
-	s.productRepo.CommitTrans(tx)
-	return transaction, nil
-}
 
-func (s *transactionService) Summary(fromDate string, toDate string) (model.SummaryResponse, error) {
-	transactions, err := s.transactionRepo.GetAll(fromDate, toDate)
+	seq, err := s.invoiceRepo.NextNumber(tx, s.checkoutConfig.StoreCode, invoice.Period(now))
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	transaction.StoreCode = s.checkoutConfig.StoreCode
+	transaction.InvoiceNumber = invoice.Format(s.checkoutConfig.InvoiceFormat, s.checkoutConfig.StoreCode, now, seq)
+
+	err = s.transactionRepo.Create(tx, &transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	err = s.rollupRepo.Add(tx, transaction.ID)
 	if err != nil {
-		return model.SummaryResponse{}, err
+		return model.Transaction{}, err
 	}
-	var summary model.SummaryResponse
-	var productTerlaris = map[string]int{}
-	for _, transaction := range transactions {
-		summary.TotalRevenue += transaction.TotalAmount
-		summary.TotalTransaction += 1
 
-		for _, detail := range transaction.Details {
-			product, err := s.productRepo.GetByID(detail.ProductID)
+	if checkoutRequest.Reservation != "" {
+		err = s.reservationRepo.ReleaseReference(tx, checkoutRequest.Reservation)
+		if err != nil {
+			return model.Transaction{}, err
+		}
+	}
+	if checkoutRequest.CartID != 0 {
+		err = s.reservationRepo.ReleaseCart(tx, checkoutRequest.CartID)
+		if err != nil {
+			return model.Transaction{}, err
+		}
+	}
+
+	if transaction.CustomerID != nil {
+		if checkoutRequest.RedeemPoints > 0 {
+			err = s.loyaltyService.Redeem(tx, *transaction.CustomerID, transaction.ID, checkoutRequest.RedeemPoints)
 			if err != nil {
-				return model.SummaryResponse{}, err
+				return model.Transaction{}, err
 			}
-			productTerlaris[product.Name] += detail.Quantity
+			transaction.PointsRedeemed = checkoutRequest.RedeemPoints
+		}
+		transaction.PointsEarned, err = s.loyaltyService.Earn(tx, *transaction.CustomerID, transaction.ID, transaction.TotalAmount)
+		if err != nil {
+			return model.Transaction{}, err
+		}
+	}
+	return transaction, nil
+}
+
+// includedTax returns the tax portion of a tax-inclusive amount.
+func (s *transactionService) includedTax(amount int) int {
+	if s.checkoutConfig.TaxRate <= 0 {
+		return 0
+	}
+	rate := s.checkoutConfig.TaxRate
+	return int(float64(amount)*rate/(100+rate) + 0.5)
+}
+
+// settlePayments validates the tenders against the total and fills in the paid
+// and change amounts. Without explicit payments the exact total is paid in
+// cash. Change can only be given out of cash.
+func settlePayments(transaction *model.Transaction, payments []model.Payment) error {
+	if len(payments) == 0 && transaction.TotalAmount > 0 {
+		payments = []model.Payment{{Method: model.PaymentMethodCash, Amount: transaction.TotalAmount}}
+	}
+
+	cash := 0
+	transaction.PaidAmount = 0
+	for i := range payments {
+		payments[i].Method = strings.ToLower(strings.TrimSpace(payments[i].Method))
+		if payments[i].Method == "" {
+			return errors.New("payment method is required")
+		}
+		if payments[i].Amount <= 0 {
+			return errors.New("payment amount must be greater than zero")
+		}
+		if payments[i].Method == model.PaymentMethodCash {
+			cash += payments[i].Amount
+		}
+		transaction.PaidAmount += payments[i].Amount
+	}
+	if transaction.PaidAmount < transaction.TotalAmount {
+		return errors.New("payment amount not enough")
+	}
+
+	change := transaction.PaidAmount - transaction.TotalAmount
+	if change > cash {
+		return errors.New("change can only be given for cash payments")
+	}
+	transaction.ChangeAmount = change
+	transaction.Payments = payments
+	return nil
+}
+
+func (s *transactionService) Refund(id int, request *model.RefundRequest) (*model.Transaction, error) {
+	transaction, err := s.transactionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	cashier := strings.TrimSpace(request.Cashier)
+	if cashier == "" && transaction.ShiftID != nil {
+		shift, err := s.shiftRepo.GetByID(*transaction.ShiftID)
+		if err != nil {
+			return nil, err
+		}
+		cashier = shift.Cashier
+	}
+	// Stock goes back to the location it was sold from
+	var locationID int
+	if transaction.LocationID != nil {
+		locationID = *transaction.LocationID
+	} else {
+		location, err := s.locationRepo.GetByCode(s.checkoutConfig.DefaultLocation)
+		if err != nil {
+			return nil, err
 		}
+		locationID = location.ID
 	}
 
-	for productName, qtyTerjual := range productTerlaris {
-		if qtyTerjual > summary.ProductTerlaris.QtyTerjual {
-			summary.ProductTerlaris.Name = productName
-			summary.ProductTerlaris.QtyTerjual = qtyTerjual
+	tx, err := s.productRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	// The money is paid out of the drawer of the shift open now, which may
+	// not be the one the sale was made in
+	var refundShiftID *int
+	if cashier != "" {
+		shift, err := s.shiftRepo.GetOpenByCashier(tx, cashier)
+		if err != nil {
+			s.productRepo.RollbackTrans(tx)
+			return nil, err
+		}
+		refundShiftID = &shift.ID
+	}
+	err = s.transactionRepo.MarkRefunded(tx, id, refundShiftID)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	// A refund is booked into today, which must still be open
+	today := time.Now().In(s.checkoutConfig.Timezone).Format(period.DateLayout)
+	err = s.zReportRepo.CheckOpen(tx, locationID, today)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.rollupRepo.Refund(tx, id)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	for _, detail := range transaction.Details {
+		err = s.stockRepo.Adjust(tx, locationID, detail.ProductID, detail.Quantity)
+		if err != nil {
+			s.productRepo.RollbackTrans(tx)
+			return nil, err
+		}
+		err = s.batchService.Release(tx, detail.Batches)
+		if err != nil {
+			s.productRepo.RollbackTrans(tx)
+			return nil, err
 		}
 	}
-	return summary, nil
+	err = s.loyaltyService.Reverse(tx, id)
+	if err != nil {
+		s.productRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.productRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	refunded, err := s.transactionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.salesFeed.Publish(model.SalesEventRefund, *refunded)
+	return refunded, nil
+}
+
+func (s *transactionService) GetByID(id int) (*model.Transaction, error) {
+	return s.transactionRepo.GetByID(id)
+}
+
+func (s *transactionService) GetByInvoiceNumber(invoiceNumber string) (*model.Transaction, error) {
+	return s.transactionRepo.GetByInvoiceNumber(s.checkoutConfig.StoreCode, invoiceNumber)
 }
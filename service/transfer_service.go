@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+)
+
+type TransferServiceInterface interface {
+	Create(transfer *model.StockTransfer) error
+	GetAll(status string, locationID int) ([]model.StockTransfer, error)
+	GetByID(id int) (*model.StockTransfer, error)
+	Receive(id int) (*model.StockTransfer, error)
+	Cancel(id int) (*model.StockTransfer, error)
+}
+
+type transferService struct {
+	transferRepo    repository.TransferRepositoryInterface
+	locationRepo    repository.LocationRepositoryInterface
+	stockRepo       repository.StockRepositoryInterface
+	productRepo     repository.ProductRepositoryInterface
+	reservationRepo repository.ReservationRepositoryInterface
+	batchRepo       repository.BatchRepositoryInterface
+	batchService    BatchServiceInterface
+}
+
+func NewTransferService(transferRepo repository.TransferRepositoryInterface, locationRepo repository.LocationRepositoryInterface, stockRepo repository.StockRepositoryInterface, productRepo repository.ProductRepositoryInterface, reservationRepo repository.ReservationRepositoryInterface, batchRepo repository.BatchRepositoryInterface, batchService BatchServiceInterface) TransferServiceInterface {
+	return &transferService{
+		transferRepo:    transferRepo,
+		locationRepo:    locationRepo,
+		stockRepo:       stockRepo,
+		productRepo:     productRepo,
+		reservationRepo: reservationRepo,
+		batchRepo:       batchRepo,
+		batchService:    batchService,
+	}
+}
+
+// Create ships the items out of the source location. While in transit the
+// stock is not counted at either location, so it cannot be sold. Batches are
+// picked earliest expiry first and travel with the transfer.
+func (s *transferService) Create(transfer *model.StockTransfer) error {
+	if transfer.FromLocationID == transfer.ToLocationID {
+		return errors.New("source and destination location must differ")
+	}
+	if len(transfer.Items) == 0 {
+		return errors.New("items are required")
+	}
+	_, err := s.locationRepo.GetByID(transfer.FromLocationID)
+	if err != nil {
+		return err
+	}
+	_, err = s.locationRepo.GetByID(transfer.ToLocationID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.transferRepo.BeginTrans()
+	if err != nil {
+		return err
+	}
+	var allocations []model.BatchAllocation
+	for _, item := range transfer.Items {
+		if item.Quantity <= 0 {
+			s.transferRepo.RollbackTrans(tx)
+			return errors.New("quantity must be greater than zero")
+		}
+		product, err := s.productRepo.LockByID(tx, item.ProductID)
+		if err != nil {
+			s.transferRepo.RollbackTrans(tx)
+			return err
+		}
+		reserved, err := s.reservationRepo.GetReserved(tx, product.ID, 0, "")
+		if err != nil {
+			s.transferRepo.RollbackTrans(tx)
+			return err
+		}
+		if product.Stock-reserved < item.Quantity {
+			s.transferRepo.RollbackTrans(tx)
+			return errors.New("product stock not enough")
+		}
+		batches, err := s.batchService.Allocate(tx, item.ProductID, transfer.FromLocationID, item.Quantity)
+		if err != nil {
+			s.transferRepo.RollbackTrans(tx)
+			return err
+		}
+		allocations = append(allocations, batches...)
+		err = s.stockRepo.Adjust(tx, transfer.FromLocationID, item.ProductID, -item.Quantity)
+		if err != nil {
+			s.transferRepo.RollbackTrans(tx)
+			return err
+		}
+	}
+	transfer.Status = model.TransferStatusInTransit
+	err = s.transferRepo.Create(tx, transfer)
+	if err != nil {
+		s.transferRepo.RollbackTrans(tx)
+		return err
+	}
+	err = s.batchRepo.SaveTransferAllocations(tx, transfer.ID, allocations)
+	if err != nil {
+		s.transferRepo.RollbackTrans(tx)
+		return err
+	}
+	return s.transferRepo.CommitTrans(tx)
+}
+
+func (s *transferService) GetAll(status string, locationID int) ([]model.StockTransfer, error) {
+	switch status {
+	case "", model.TransferStatusInTransit, model.TransferStatusReceived, model.TransferStatusCancelled:
+	default:
+		return nil, errors.New("status must be in_transit, received or cancelled")
+	}
+	return s.transferRepo.GetAll(status, locationID)
+}
+
+func (s *transferService) GetByID(id int) (*model.StockTransfer, error) {
+	return s.transferRepo.GetByID(id)
+}
+
+// Receive books the items and their batches into the destination location.
+func (s *transferService) Receive(id int) (*model.StockTransfer, error) {
+	return s.complete(id, model.TransferStatusReceived)
+}
+
+// Cancel returns the items to the source location and its batches.
+func (s *transferService) Cancel(id int) (*model.StockTransfer, error) {
+	return s.complete(id, model.TransferStatusCancelled)
+}
+
+func (s *transferService) complete(id int, status string) (*model.StockTransfer, error) {
+	tx, err := s.transferRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := s.transferRepo.LockInTransit(tx, id)
+	if err != nil {
+		s.transferRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	locationID := transfer.ToLocationID
+	if status == model.TransferStatusCancelled {
+		locationID = transfer.FromLocationID
+	}
+	for _, item := range transfer.Items {
+		err = s.stockRepo.Adjust(tx, locationID, item.ProductID, item.Quantity)
+		if err != nil {
+			s.transferRepo.RollbackTrans(tx)
+			return nil, err
+		}
+	}
+	allocations, err := s.batchRepo.GetTransferAllocations(tx, transfer.ID)
+	if err != nil {
+		s.transferRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	if status == model.TransferStatusCancelled {
+		err = s.batchService.Release(tx, allocations)
+	} else {
+		for _, allocation := range allocations {
+			err = s.batchRepo.CopyTo(tx, allocation.BatchID, transfer.ToLocationID, allocation.Quantity)
+			if err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		s.transferRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	transfer.Status = status
+	err = s.transferRepo.Complete(tx, transfer)
+	if err != nil {
+		s.transferRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.transferRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
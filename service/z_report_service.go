@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"product-api/model"
+	"product-api/repository"
+	"product-api/utils/period"
+	"product-api/utils/receipt"
+	"strings"
+	"time"
+)
+
+var ErrFutureBusinessDate = errors.New("date must not be in the future")
+
+type ZReportServiceInterface interface {
+	CloseDay(request *model.CloseDayRequest) (*model.ZReport, error)
+	GetAll(locationID int) ([]model.ZReport, error)
+	GetByID(id int) (*model.ZReport, error)
+	Print(id int, format string, paper int) ([]byte, string, error)
+}
+
+type zReportService struct {
+	zReportRepo     repository.ZReportRepositoryInterface
+	locationRepo    repository.LocationRepositoryInterface
+	receiptRepo     repository.ReceiptRepositoryInterface
+	defaultLocation string
+	timezone        *time.Location
+}
+
+// NewZReportService creates the Z-report service. Business days run from
+// midnight to midnight in timezone; days closed without a location are
+// closed at the defaultLocation code.
+func NewZReportService(zReportRepo repository.ZReportRepositoryInterface, locationRepo repository.LocationRepositoryInterface, receiptRepo repository.ReceiptRepositoryInterface, defaultLocation string, timezone *time.Location) ZReportServiceInterface {
+	return &zReportService{
+		zReportRepo:     zReportRepo,
+		locationRepo:    locationRepo,
+		receiptRepo:     receiptRepo,
+		defaultLocation: defaultLocation,
+		timezone:        timezone,
+	}
+}
+
+// CloseDay computes and stores the Z-report of a business day. Once closed,
+// no checkout or refund can be booked into that day at the location.
+func (s *zReportService) CloseDay(request *model.CloseDayRequest) (*model.ZReport, error) {
+	request.ClosedBy = strings.TrimSpace(request.ClosedBy)
+	if request.ClosedBy == "" {
+		return nil, errors.New("closed_by is required")
+	}
+
+	today := period.StartOfDay(time.Now(), s.timezone)
+	start := today
+	if request.Date != "" {
+		var err error
+		start, err = period.ParseDate(request.Date, s.timezone)
+		if err != nil {
+			return nil, errors.New("date must be in YYYY-MM-DD format")
+		}
+		if start.After(today) {
+			return nil, ErrFutureBusinessDate
+		}
+	}
+
+	if request.LocationID == 0 {
+		location, err := s.locationRepo.GetByCode(s.defaultLocation)
+		if err != nil {
+			return nil, err
+		}
+		request.LocationID = location.ID
+	}
+
+	report := model.ZReport{
+		LocationID:   request.LocationID,
+		BusinessDate: start.Format(period.DateLayout),
+		Timezone:     s.timezone.String(),
+		PeriodStart:  start,
+		PeriodEnd:    start.AddDate(0, 0, 1),
+		ClosedBy:     request.ClosedBy,
+		Note:         strings.TrimSpace(request.Note),
+	}
+
+	tx, err := s.zReportRepo.BeginTrans()
+	if err != nil {
+		return nil, err
+	}
+	err = s.zReportRepo.LockLocation(tx, report.LocationID)
+	if err != nil {
+		s.zReportRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.zReportRepo.Compute(tx, &report)
+	if err != nil {
+		s.zReportRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.zReportRepo.Create(tx, &report)
+	if err != nil {
+		s.zReportRepo.RollbackTrans(tx)
+		return nil, err
+	}
+	err = s.zReportRepo.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	s.localize(&report)
+	return &report, nil
+}
+
+func (s *zReportService) GetAll(locationID int) ([]model.ZReport, error) {
+	reports, err := s.zReportRepo.GetAll(locationID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reports {
+		s.localize(&reports[i])
+	}
+	return reports, nil
+}
+
+func (s *zReportService) GetByID(id int) (*model.ZReport, error) {
+	report, err := s.zReportRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.localize(report)
+	return report, nil
+}
+
+// Print renders a stored Z-report for a receipt printer as text or pdf.
+func (s *zReportService) Print(id int, format string, paper int) ([]byte, string, error) {
+	width, err := receipt.Width(paper)
+	if err != nil {
+		return nil, "", err
+	}
+	if format != model.ReceiptFormatText && format != model.ReceiptFormatPDF {
+		return nil, "", receipt.ErrUnsupportedFormat
+	}
+	report, err := s.GetByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+	location, err := s.locationRepo.GetByID(report.LocationID)
+	if err != nil {
+		return nil, "", err
+	}
+	settings, err := s.receiptRepo.GetSettings()
+	if err != nil {
+		return nil, "", err
+	}
+
+	text, err := receipt.RenderZReport(model.ZReportPrint{Store: *settings, Location: *location, Report: *report, Width: width})
+	if err != nil {
+		return nil, "", err
+	}
+	if format == model.ReceiptFormatText {
+		return text, "text/plain; charset=utf-8", nil
+	}
+	body, err := receipt.RenderPDF(text, paper, width)
+	return body, "application/pdf", err
+}
+
+// localize shows the times of a report in the timezone it was closed in.
+func (s *zReportService) localize(report *model.ZReport) {
+	loc, err := period.Location(report.Timezone, s.timezone)
+	if err != nil {
+		loc = s.timezone
+	}
+	report.PeriodStart = report.PeriodStart.In(loc)
+	report.PeriodEnd = report.PeriodEnd.In(loc)
+	report.ClosedAt = report.ClosedAt.In(loc)
+}
@@ -0,0 +1,99 @@
+package escpos
+
+import "bytes"
+
+const (
+	esc = 0x1B
+	gs  = 0x1D
+)
+
+const (
+	AlignLeft   byte = 0
+	AlignCenter byte = 1
+	AlignRight  byte = 2
+)
+
+// Builder accumulates an ESC/POS command stream for thermal printers.
+type Builder struct {
+	buf bytes.Buffer
+}
+
+func New() *Builder {
+	b := &Builder{}
+	b.buf.Write([]byte{esc, '@'})
+	return b
+}
+
+func (b *Builder) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func (b *Builder) Align(align byte) *Builder {
+	b.buf.Write([]byte{esc, 'a', align})
+	return b
+}
+
+func (b *Builder) Bold(on bool) *Builder {
+	b.buf.Write([]byte{esc, 'E', flag(on)})
+	return b
+}
+
+// DoubleHeight toggles double height characters; the line width is unchanged.
+func (b *Builder) DoubleHeight(on bool) *Builder {
+	size := byte(0x00)
+	if on {
+		size = 0x01
+	}
+	b.buf.Write([]byte{gs, '!', size})
+	return b
+}
+
+// Text writes s followed by a line feed. Characters outside ASCII are
+// replaced with '?' because the printer code page is not configured.
+func (b *Builder) Text(s string) *Builder {
+	for _, r := range s {
+		if r == '\n' || (r >= 0x20 && r < 0x7F) {
+			b.buf.WriteByte(byte(r))
+		} else {
+			b.buf.WriteByte('?')
+		}
+	}
+	b.buf.WriteByte('\n')
+	return b
+}
+
+func (b *Builder) Feed(lines byte) *Builder {
+	b.buf.Write([]byte{esc, 'd', lines})
+	return b
+}
+
+// QRCode prints data as a model 2 QR code with the given module size (1-16).
+func (b *Builder) QRCode(data string, size byte) *Builder {
+	length := len(data) + 3
+	b.buf.Write([]byte{gs, '(', 'k', 4, 0, '1', 'A', '2', 0})
+	b.buf.Write([]byte{gs, '(', 'k', 3, 0, '1', 'C', size})
+	b.buf.Write([]byte{gs, '(', 'k', 3, 0, '1', 'E', '1'})
+	b.buf.Write([]byte{gs, '(', 'k', byte(length % 256), byte(length / 256), '1', 'P', '0'})
+	b.buf.WriteString(data)
+	b.buf.Write([]byte{gs, '(', 'k', 3, 0, '1', 'Q', '0'})
+	return b
+}
+
+// Cut feeds the paper past the cutter and performs a partial cut.
+func (b *Builder) Cut() *Builder {
+	b.buf.Write([]byte{gs, 'V', 'B', 3})
+	return b
+}
+
+// OpenDrawer sends a pulse to the cash drawer connected on pin 2.
+func (b *Builder) OpenDrawer() *Builder {
+	b.buf.Write([]byte{esc, 'p', 0, 25, 250})
+	return b
+}
+
+func flag(on bool) byte {
+	if on {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Column kinds decide how a value is written: id, currency and number
+// columns hold integers, percent columns hold float64 percentages. Ids are
+// written without thousand separators.
+const (
+	KindID       = "id"
+	KindText     = "text"
+	KindNumber   = "number"
+	KindCurrency = "currency"
+	KindPercent  = "percent"
+)
+
+type Column struct {
+	Header string
+	Kind   string
+}
+
+// Currency marks a single cell as an amount regardless of its column kind.
+type Currency int
+
+// Table is a report flattened for export. Cells are string, int, *int,
+// Currency, float64 or nil; a nil cell or nil *int is written empty.
+type Table struct {
+	Title   string
+	Columns []Column
+	Rows    [][]interface{}
+}
+
+func CSV(table Table) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	headers := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		headers[i] = column.Header
+	}
+	err := writer.Write(headers)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = text(cell)
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// formulaPrefixes start a formula when a spreadsheet opens the file.
+const formulaPrefixes = "=+-@\t\r"
+
+// text renders a CSV cell like Plain, but quotes text cells that would run as
+// a formula, as product and category names are user-entered. XLSX does not
+// need this: its inline string cells are never evaluated.
+func text(cell interface{}) string {
+	value, ok := cell.(string)
+	if !ok {
+		return Plain(cell)
+	}
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// Plain renders a cell without any number formatting.
+func Plain(cell interface{}) string {
+	switch value := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case int:
+		return strconv.Itoa(value)
+	case Currency:
+		return strconv.Itoa(int(value))
+	case *int:
+		if value == nil {
+			return ""
+		}
+		return strconv.Itoa(*value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return fmt.Sprint(cell)
+}
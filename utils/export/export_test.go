@@ -0,0 +1,43 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSVQuotesFormulas(t *testing.T) {
+	table := Table{
+		Columns: []Column{{Header: "Name", Kind: KindText}, {Header: "Change", Kind: KindNumber}},
+		Rows: [][]interface{}{
+			{"=HYPERLINK(\"http://example.com\")", -5000},
+			{"+62 Kopi", 1},
+			{"-Diskon", 2},
+			{"@SUM(A1)", 3},
+			{"Kopi Susu", 4},
+		},
+	}
+	got, err := CSV(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"Name,Change",
+		`"'=HYPERLINK(""http://example.com"")",-5000`,
+		"'+62 Kopi,1",
+		"'-Diskon,2",
+		"'@SUM(A1),3",
+		"Kopi Susu,4",
+		"",
+	}, "\n")
+	if string(got) != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestXLSXKeepsText(t *testing.T) {
+	var out strings.Builder
+	writeCell(&out, "A2", "-Diskon-", KindText)
+	if !strings.Contains(out.String(), `t="inlineStr"`) || !strings.Contains(out.String(), "<t xml:space=\"preserve\">-Diskon-</t>") {
+		t.Errorf("cell = %s, want an unquoted inline string", out.String())
+	}
+}
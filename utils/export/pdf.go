@@ -0,0 +1,163 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"product-api/utils/receipt"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	FormatPDF = "pdf"
+
+	pdfMargin     = 10.0
+	pdfRowHeight  = 6.0
+	pdfFontSize   = 8.0
+	pdfTitleSize  = 14.0
+	pdfHeaderSize = 9.0
+)
+
+// Header is printed above the table on the first page of a PDF report.
+type Header struct {
+	StoreName string
+	Address   string
+	Phone     string
+	Title     string
+	Subtitle  string
+}
+
+// PDF lays the table out on A4 pages, landscape when it has more than six
+// columns. Text columns get twice the width of number columns, the column
+// headers are repeated on every page and amounts use dot thousand separators.
+func PDF(header Header, table Table) ([]byte, error) {
+	orientation := "P"
+	if len(table.Columns) > 6 {
+		orientation = "L"
+	}
+	pdf := fpdf.New(orientation, "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+	usable := pageWidth - 2*pdfMargin
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin)
+		pdf.SetFont("Helvetica", "", pdfFontSize)
+		pdf.CellFormat(0, 4, fmt.Sprintf("Halaman %d", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", pdfTitleSize)
+	pdf.CellFormat(usable, 7, translate(header.StoreName), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", pdfHeaderSize)
+	for _, line := range []string{header.Address, header.Phone} {
+		if line != "" {
+			pdf.CellFormat(usable, 4.5, translate(line), "", 1, "L", false, 0, "")
+		}
+	}
+	pdf.Ln(3)
+	pdf.SetFont("Helvetica", "B", pdfHeaderSize+2)
+	pdf.CellFormat(usable, 6, translate(header.Title), "", 1, "L", false, 0, "")
+	if header.Subtitle != "" {
+		pdf.SetFont("Helvetica", "", pdfHeaderSize)
+		pdf.CellFormat(usable, 5, translate(header.Subtitle), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	widths := columnWidths(table.Columns, usable)
+	drawHeader := func() {
+		pdf.SetFont("Helvetica", "B", pdfFontSize)
+		pdf.SetFillColor(230, 230, 230)
+		for i, column := range table.Columns {
+			pdf.CellFormat(widths[i], pdfRowHeight, translate(column.Header), "1", 0, align(column.Kind), true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", pdfFontSize)
+	}
+	drawHeader()
+	for _, row := range table.Rows {
+		if pdf.GetY()+pdfRowHeight > pageHeight-2*pdfMargin {
+			pdf.AddPage()
+			drawHeader()
+		}
+		for i, column := range table.Columns {
+			var cell interface{}
+			if i < len(row) {
+				cell = row[i]
+			}
+			value := fit(pdf, translate, Formatted(cell, column.Kind), widths[i]-2)
+			pdf.CellFormat(widths[i], pdfRowHeight, value, "1", 0, align(column.Kind), false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Formatted renders a cell for reading: amounts and numbers with dot
+// thousand separators and percentages with two decimals.
+func Formatted(cell interface{}, kind string) string {
+	if value, ok := cell.(*int); ok {
+		if value == nil {
+			return ""
+		}
+		cell = *value
+	}
+	switch value := cell.(type) {
+	case Currency:
+		return receipt.Rupiah(int(value))
+	case int:
+		if kind == KindCurrency || kind == KindNumber {
+			return receipt.Rupiah(value)
+		}
+	case float64:
+		if kind == KindPercent {
+			return strconv.FormatFloat(value, 'f', 2, 64) + "%"
+		}
+	}
+	return Plain(cell)
+}
+
+// fit cuts text to the runes that fit width in the current font and returns
+// it translated to the font's code page.
+func fit(pdf *fpdf.Fpdf, translate func(string) string, text string, width float64) string {
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(translate(string(runes))) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return translate(string(runes))
+}
+
+func columnWidths(columns []Column, usable float64) []float64 {
+	units := 0.0
+	for _, column := range columns {
+		units += columnUnits(column)
+	}
+	widths := make([]float64, len(columns))
+	for i, column := range columns {
+		widths[i] = usable * columnUnits(column) / units
+	}
+	return widths
+}
+
+func columnUnits(column Column) float64 {
+	if column.Kind == KindText {
+		return 2
+	}
+	return 1
+}
+
+func align(kind string) string {
+	if kind == KindText {
+		return "L"
+	}
+	return "R"
+}
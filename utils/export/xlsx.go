@@ -0,0 +1,209 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+const FormatXLSX = "xlsx"
+
+// Cell styles, indexes into cellXfs of xlsxStyles.
+const (
+	styleDefault = iota
+	styleHeader
+	styleNumber
+	styleCurrency
+	stylePercent
+)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+// Currency cells are shown as "Rp 1,500,000" (separators follow the reader's
+// locale), percent cells hold the percentage itself, e.g. 12.5 for 12.5%.
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="2">
+<numFmt numFmtId="164" formatCode="&quot;Rp&quot;\ #,##0;\-&quot;Rp&quot;\ #,##0"/>
+<numFmt numFmtId="165" formatCode="0.00&quot;%&quot;"/>
+</numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="5">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+<xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+</cellXfs>
+</styleSheet>`
+
+// XLSX writes the table as a single sheet workbook with a bold header row and
+// number formats per column kind.
+func XLSX(table Table) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(table.Title)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(table)},
+	}
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		_, err = writer.Write([]byte(file.content))
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := archive.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func xlsxWorkbook(title string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="` + escapeXML(sheetName(title)) + `" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+}
+
+func xlsxSheet(table Table) string {
+	var out strings.Builder
+	out.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>
+<cols>`)
+	for i, column := range table.Columns {
+		width := 14
+		if column.Kind == KindText {
+			width = 28
+		}
+		index := strconv.Itoa(i + 1)
+		out.WriteString(`<col min="` + index + `" max="` + index + `" width="` + strconv.Itoa(width) + `" customWidth="1"/>`)
+	}
+	out.WriteString("</cols>\n<sheetData>\n")
+
+	out.WriteString(`<row r="1">`)
+	for i, column := range table.Columns {
+		writeStringCell(&out, cellRef(i, 1), column.Header, styleHeader)
+	}
+	out.WriteString("</row>\n")
+	for r, row := range table.Rows {
+		number := r + 2
+		out.WriteString(`<row r="` + strconv.Itoa(number) + `">`)
+		for i, cell := range row {
+			kind := KindText
+			if i < len(table.Columns) {
+				kind = table.Columns[i].Kind
+			}
+			writeCell(&out, cellRef(i, number), cell, kind)
+		}
+		out.WriteString("</row>\n")
+	}
+	out.WriteString("</sheetData>\n</worksheet>")
+	return out.String()
+}
+
+func writeCell(out *strings.Builder, ref string, cell interface{}, kind string) {
+	style := styleDefault
+	switch kind {
+	case KindNumber:
+		style = styleNumber
+	case KindCurrency:
+		style = styleCurrency
+	case KindPercent:
+		style = stylePercent
+	}
+	switch value := cell.(type) {
+	case nil:
+		return
+	case *int:
+		if value == nil {
+			return
+		}
+		writeNumberCell(out, ref, strconv.Itoa(*value), style)
+	case int:
+		writeNumberCell(out, ref, strconv.Itoa(value), style)
+	case Currency:
+		writeNumberCell(out, ref, strconv.Itoa(int(value)), styleCurrency)
+	case float64:
+		writeNumberCell(out, ref, strconv.FormatFloat(value, 'f', -1, 64), style)
+	default:
+		writeStringCell(out, ref, Plain(cell), styleDefault)
+	}
+}
+
+func writeNumberCell(out *strings.Builder, ref string, value string, style int) {
+	out.WriteString(`<c r="` + ref + `" s="` + strconv.Itoa(style) + `"><v>` + value + `</v></c>`)
+}
+
+func writeStringCell(out *strings.Builder, ref string, value string, style int) {
+	out.WriteString(`<c r="` + ref + `" s="` + strconv.Itoa(style) + `" t="inlineStr"><is><t xml:space="preserve">` + escapeXML(value) + `</t></is></c>`)
+}
+
+// cellRef returns the A1 reference of a zero based column and a row number.
+func cellRef(column int, row int) string {
+	name := ""
+	for column >= 0 {
+		name = string(rune('A'+column%26)) + name
+		column = column/26 - 1
+	}
+	return name + strconv.Itoa(row)
+}
+
+// sheetName strips the characters Excel does not allow in sheet names and
+// cuts the name to 31 characters.
+func sheetName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, title)
+	if name == "" {
+		name = "Report"
+	}
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	return name
+}
+
+func escapeXML(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
@@ -0,0 +1,58 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultFormat = "INV/{STORE}/{YYYY}/{MM}/{SEQ:6}"
+
+var seqPattern = regexp.MustCompile(`\{SEQ(?::(\d+))?\}`)
+
+// requiredPlaceholders keep numbers unique across months, as the sequence is
+// reset every month. Numbers only need to be unique per store, so {STORE} is
+// optional.
+var requiredPlaceholders = []string{"{YYYY}", "{MM}"}
+
+// Validate checks that the format contains exactly one sequence placeholder
+// and the placeholders that make the number unique within a store.
+func Validate(format string) error {
+	if len(seqPattern.FindAllString(format, -1)) != 1 {
+		return errors.New("invoice format must contain exactly one {SEQ} or {SEQ:n} placeholder")
+	}
+	for _, placeholder := range requiredPlaceholders {
+		if !strings.Contains(format, placeholder) {
+			return fmt.Errorf("invoice format must contain %s", placeholder)
+		}
+	}
+	return nil
+}
+
+// Period returns the key the sequence is reset on, one sequence per month.
+func Period(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// Format renders an invoice number. Supported placeholders are {STORE},
+// {YYYY}, {YY}, {MM}, {DD} and {SEQ:n} where n is the zero padded width.
+func Format(format string, store string, t time.Time, seq int) string {
+	replacer := strings.NewReplacer(
+		"{STORE}", store,
+		"{YYYY}", t.Format("2006"),
+		"{YY}", t.Format("06"),
+		"{MM}", t.Format("01"),
+		"{DD}", t.Format("02"),
+	)
+	number := replacer.Replace(format)
+	return seqPattern.ReplaceAllStringFunc(number, func(match string) string {
+		width := 0
+		if groups := seqPattern.FindStringSubmatch(match); groups[1] != "" {
+			width, _ = strconv.Atoi(groups[1])
+		}
+		return fmt.Sprintf("%0*d", width, seq)
+	})
+}
@@ -0,0 +1,99 @@
+package period
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// aliases maps the Indonesian zone abbreviations to their IANA names.
+var aliases = map[string]string{
+	"WIB":  "Asia/Jakarta",
+	"WITA": "Asia/Makassar",
+	"WIT":  "Asia/Jayapura",
+}
+
+// Location resolves a timezone name such as "Asia/Jakarta" or "WITA". An empty
+// name resolves to fallback.
+func Location(name string, fallback *time.Location) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fallback, nil
+	}
+	if alias, ok := aliases[strings.ToUpper(name)]; ok {
+		name = alias
+	}
+	return time.LoadLocation(name)
+}
+
+// ParseDate parses a YYYY-MM-DD date as the start of that day in loc.
+func ParseDate(value string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(DateLayout, value, loc)
+}
+
+// StartOfDay returns midnight of the day containing t in loc.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// Day returns the half-open range [start, end) of the day containing t in
+// loc. The end is the next midnight, so days of 23 or 25 hours are handled.
+func Day(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	start := StartOfDay(t, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// DefaultRangeDays is the length of the range used when no dates are given.
+const DefaultRangeDays = 7
+
+// Range resolves the inclusive start and end dates of a report into the
+// half-open range [from, to) in loc. A missing end date defaults to today and
+// a missing start date to DefaultRangeDays days up to the end date. Ranges
+// longer than maxDays are rejected unless maxDays is zero.
+func Range(startDate string, endDate string, loc *time.Location, now time.Time, maxDays int) (time.Time, time.Time, error) {
+	var from, end time.Time
+	var err error
+	if strings.TrimSpace(endDate) == "" {
+		end = StartOfDay(now, loc)
+	} else {
+		end, err = ParseDate(strings.TrimSpace(endDate), loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("end_date must be a valid date in YYYY-MM-DD format")
+		}
+	}
+	if strings.TrimSpace(startDate) == "" {
+		from = end.AddDate(0, 0, -(DefaultRangeDays - 1))
+	} else {
+		from, err = ParseDate(strings.TrimSpace(startDate), loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("start_date must be a valid date in YYYY-MM-DD format")
+		}
+	}
+	if end.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("end_date must not be before start_date")
+	}
+
+	to := end.AddDate(0, 0, 1)
+	if maxDays > 0 && to.After(from.AddDate(0, 0, maxDays)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range must not exceed %d days", maxDays)
+	}
+	return from, to, nil
+}
+
+// Previous returns the range of the same length that ends where [from, to)
+// starts. The length is counted in calendar days so DST changes do not shift
+// the boundaries off midnight.
+func Previous(from time.Time, to time.Time) (time.Time, time.Time) {
+	days := int(to.Sub(from).Hours()/24 + 0.5)
+	return from.AddDate(0, 0, -days), from
+}
+
+// LastYear returns the same range one year earlier. 29 February moves to
+// 1 March in years without it.
+func LastYear(from time.Time, to time.Time) (time.Time, time.Time) {
+	return from.AddDate(-1, 0, 0), to.AddDate(-1, 0, 0)
+}
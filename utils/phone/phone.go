@@ -0,0 +1,46 @@
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultCountryCode is used for numbers written in local format (0812...).
+const DefaultCountryCode = "62"
+
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// Normalize converts a phone number into E.164 format, e.g. "0812-3456-7890"
+// becomes "+6281234567890".
+func Normalize(raw string) (string, error) {
+	var digits strings.Builder
+	raw = strings.TrimSpace(raw)
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case strings.HasPrefix(raw, "+"):
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	case strings.HasPrefix(number, "0"):
+		number = DefaultCountryCode + number[1:]
+	case strings.HasPrefix(number, DefaultCountryCode):
+	default:
+		number = DefaultCountryCode + number
+	}
+
+	// E.164 allows at most 15 digits; anything under 8 cannot be a real subscriber number
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+	return "+" + number, nil
+}
@@ -0,0 +1,77 @@
+package receipt
+
+import (
+	"fmt"
+	"product-api/model"
+	"product-api/utils/escpos"
+	"strings"
+)
+
+// RenderESCPOS lays out the receipt as an ESC/POS byte stream with the invoice
+// number as QR code. When openDrawer is set and the customer paid cash, the
+// cash drawer is kicked; reprints leave it unset so the drawer stays shut.
+func RenderESCPOS(data model.Receipt, openDrawer bool) []byte {
+	w := data.Width
+	t := data.Transaction
+	p := escpos.New()
+
+	p.Align(escpos.AlignCenter).Bold(true).DoubleHeight(true)
+	for _, line := range Wrap(w, data.Store.StoreName) {
+		p.Text(line)
+	}
+	p.DoubleHeight(false).Bold(false)
+	for _, line := range Wrap(w, data.Store.Address) {
+		p.Text(line)
+	}
+	if data.Store.Phone != "" {
+		p.Text(data.Store.Phone)
+	}
+
+	p.Align(escpos.AlignLeft)
+	p.Text(Line(w, "="))
+	p.Text(LeftRight(w, "No", t.InvoiceNumber))
+	p.Text(LeftRight(w, "Tanggal", datetime(t.CreatedAt)))
+	p.Text(Line(w, "-"))
+	for _, detail := range t.Details {
+		for _, line := range Wrap(w, detail.ProductName) {
+			p.Text(line)
+		}
+		p.Text(LeftRight(w, fmt.Sprintf("  %d x %s", detail.Quantity, Rupiah(detail.Price)), Rupiah(detail.Subtotal)))
+	}
+	p.Text(Line(w, "-"))
+	p.Text(LeftRight(w, "Subtotal", Rupiah(data.Subtotal)))
+	if t.DiscountAmount != 0 {
+		p.Text(LeftRight(w, "Diskon", "-"+Rupiah(t.DiscountAmount)))
+	}
+	p.Bold(true).DoubleHeight(true)
+	p.Text(LeftRight(w, "TOTAL", Rupiah(t.TotalAmount)))
+	p.DoubleHeight(false).Bold(false)
+	if t.TaxAmount != 0 {
+		p.Text(LeftRight(w, "Termasuk PPN", Rupiah(t.TaxAmount)))
+	}
+	p.Text(Line(w, "-"))
+
+	paidCash := false
+	for _, payment := range t.Payments {
+		p.Text(LeftRight(w, strings.ToUpper(payment.Method), Rupiah(payment.Amount)))
+		if payment.Method == model.PaymentMethodCash {
+			paidCash = true
+		}
+	}
+	p.Text(LeftRight(w, "Kembali", Rupiah(t.ChangeAmount)))
+	p.Text(Line(w, "="))
+
+	p.Align(escpos.AlignCenter)
+	if t.InvoiceNumber != "" {
+		p.QRCode(t.InvoiceNumber, 6)
+		p.Text(t.InvoiceNumber)
+	}
+	for _, line := range Wrap(w, data.Store.Footer) {
+		p.Text(line)
+	}
+	p.Feed(3).Cut()
+	if openDrawer && paidCash {
+		p.OpenDrawer()
+	}
+	return p.Bytes()
+}
@@ -0,0 +1,115 @@
+package receipt
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"product-api/model"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// drawerKick is the pulse RenderESCPOS sends to open the cash drawer.
+var drawerKick = []byte{0x1B, 'p', 0, 25, 250}
+
+func testReceipt(paper int, payments ...model.Payment) model.Receipt {
+	width, err := Width(paper)
+	if err != nil {
+		panic(err)
+	}
+	return model.Receipt{
+		Store: model.ReceiptSettings{
+			StoreName: "Toko Maju Jaya",
+			Address:   "Jl. Merdeka No. 1, Bandung, Jawa Barat 40111",
+			Phone:     "022-1234567",
+			Footer:    "Terima kasih atas kunjungan Anda",
+		},
+		Transaction: model.Transaction{
+			ID:             123,
+			InvoiceNumber:  "INV/MAIN/2026/10/000123",
+			TotalAmount:    64500,
+			DiscountAmount: 5000,
+			TaxAmount:      6392,
+			PaidAmount:     70000,
+			ChangeAmount:   5500,
+			CreatedAt:      "2026-10-19T10:30:00+07:00",
+			Details: []model.TransactionDetail{
+				{ProductName: "Kopi Susu Gula Aren Ukuran Besar", Price: 17500, Quantity: 2, Subtotal: 35000},
+				{ProductName: "Roti Bakar", Price: 14500, Quantity: 1, Subtotal: 14500},
+				{ProductName: "Air Mineral", Price: 5000, Quantity: 4, Subtotal: 20000},
+			},
+			Payments: payments,
+		},
+		Subtotal: 69500,
+		Width:    width,
+		Paper:    paper,
+	}
+}
+
+func TestRenderESCPOSGolden(t *testing.T) {
+	cash := []model.Payment{{Method: model.PaymentMethodCash, Amount: 70000}}
+	nonCash := []model.Payment{{Method: "qris", Amount: 50000}, {Method: "debit", Amount: 14500}}
+
+	tests := []struct {
+		name     string
+		paper    int
+		payments []model.Payment
+	}{
+		{"58mm_cash", 58, cash},
+		{"80mm_cash", 80, cash},
+		{"58mm_non_cash", 58, nonCash},
+		{"80mm_non_cash", 80, nonCash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testReceipt(tt.paper, tt.payments...)
+			if tt.payments[0].Method != model.PaymentMethodCash {
+				data.Transaction.PaidAmount = 64500
+				data.Transaction.ChangeAmount = 0
+			}
+			got := RenderESCPOS(data, true)
+
+			golden := filepath.Join("testdata", "escpos_"+tt.name+".golden")
+			if *update {
+				err := os.WriteFile(golden, got, 0o644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file: %v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("ESC/POS output differs from %s:\ngot:  %q\nwant: %q", golden, got, want)
+			}
+		})
+	}
+}
+
+func TestRenderESCPOSDrawer(t *testing.T) {
+	cash := testReceipt(80, model.Payment{Method: model.PaymentMethodCash, Amount: 70000})
+	nonCash := testReceipt(80, model.Payment{Method: "qris", Amount: 64500})
+
+	tests := []struct {
+		name       string
+		data       model.Receipt
+		openDrawer bool
+		want       bool
+	}{
+		{"cash at checkout", cash, true, true},
+		{"cash reprint", cash, false, false},
+		{"non-cash at checkout", nonCash, true, false},
+		{"non-cash reprint", nonCash, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Contains(RenderESCPOS(tt.data, tt.openDrawer), drawerKick)
+			if got != tt.want {
+				t.Errorf("drawer kicked = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package receipt
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfMargin      = 3.0
+	pointToMM      = 0.3528
+	courierAdvance = 0.6
+	pdfLineSpacing = 1.2
+)
+
+// RenderPDF lays out a plain text receipt on a single page as wide as the
+// paper roll, sizing the monospace font so that width characters fit a line.
+func RenderPDF(text []byte, paper int, width int) ([]byte, error) {
+	lines := strings.Split(strings.TrimRight(string(text), "\n"), "\n")
+
+	usable := float64(paper) - 2*pdfMargin
+	fontSize := usable / (float64(width) * courierAdvance * pointToMM)
+	lineHeight := fontSize * pointToMM * pdfLineSpacing
+	height := float64(len(lines))*lineHeight + 2*pdfMargin
+	if height < float64(paper) {
+		height = float64(paper)
+	}
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		UnitStr: "mm",
+		Size:    fpdf.SizeType{Wd: float64(paper), Ht: height},
+	})
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+	pdf.SetFont("Courier", "", fontSize)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	for _, line := range lines {
+		pdf.CellFormat(usable, lineHeight, translate(line), "", 1, "L", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
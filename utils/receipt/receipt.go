@@ -0,0 +1,186 @@
+package receipt
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"product-api/model"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported receipt format")
+	ErrUnsupportedPaper  = errors.New("paper width must be 58 or 80")
+	ErrInvalidTemplate   = errors.New("invalid receipt template")
+)
+
+// Width returns the number of characters per line for a paper width in mm.
+func Width(paper int) (int, error) {
+	switch paper {
+	case 58:
+		return 32, nil
+	case 80:
+		return 48, nil
+	}
+	return 0, ErrUnsupportedPaper
+}
+
+// DefaultTemplate returns the built-in template for the text or html format.
+func DefaultTemplate(format string) (string, error) {
+	var name string
+	switch format {
+	case model.ReceiptFormatText:
+		name = "templates/receipt.txt.tmpl"
+	case model.ReceiptFormatHTML:
+		name = "templates/receipt.html.tmpl"
+	default:
+		return "", ErrUnsupportedFormat
+	}
+	content, err := templates.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// Validate parses a template so broken templates are rejected before saving.
+// Parse errors wrap ErrInvalidTemplate.
+func Validate(format string, content string) error {
+	var err error
+	switch format {
+	case model.ReceiptFormatText:
+		_, err = texttemplate.New("receipt").Funcs(funcs).Parse(content)
+	case model.ReceiptFormatHTML:
+		_, err = htmltemplate.New("receipt").Funcs(funcs).Parse(content)
+	default:
+		return ErrUnsupportedFormat
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+func RenderText(content string, data model.Receipt) ([]byte, error) {
+	tmpl, err := texttemplate.New("receipt").Funcs(funcs).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	return buf.Bytes(), err
+}
+
+func RenderHTML(content string, data model.Receipt) ([]byte, error) {
+	tmpl, err := htmltemplate.New("receipt").Funcs(funcs).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	return buf.Bytes(), err
+}
+
+var funcs = map[string]interface{}{
+	"rupiah":   Rupiah,
+	"center":   Center,
+	"lr":       LeftRight,
+	"line":     Line,
+	"wrap":     Wrap,
+	"datetime": datetime,
+	"upper":    strings.ToUpper,
+}
+
+// Rupiah formats an amount with dot thousand separators, e.g. 1500000 becomes
+// "1.500.000".
+func Rupiah(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.Itoa(amount)
+	var out strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out.WriteByte('.')
+		}
+		out.WriteRune(d)
+	}
+	return sign + out.String()
+}
+
+func Center(width int, s string) string {
+	length := utf8.RuneCountInString(s)
+	if length >= width {
+		return s
+	}
+	return strings.Repeat(" ", (width-length)/2) + s
+}
+
+// LeftRight puts left and right on one line separated by spaces. When they
+// do not fit the right part goes on its own right-aligned line.
+func LeftRight(width int, left string, right string) string {
+	gap := width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if gap >= 1 {
+		return left + strings.Repeat(" ", gap) + right
+	}
+	pad := width - utf8.RuneCountInString(right)
+	if pad < 0 {
+		pad = 0
+	}
+	return left + "\n" + strings.Repeat(" ", pad) + right
+}
+
+func Line(width int, char string) string {
+	return strings.Repeat(char, width)
+}
+
+// Wrap splits text into lines of at most width characters on word boundaries.
+func Wrap(width int, s string) []string {
+	lines := make([]string, 0)
+	for _, paragraph := range strings.Split(s, "\n") {
+		current := ""
+		for _, word := range strings.Fields(paragraph) {
+			for utf8.RuneCountInString(word) > width {
+				if current != "" {
+					lines = append(lines, current)
+					current = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:width]))
+				word = string(runes[width:])
+			}
+			switch {
+			case current == "":
+				current = word
+			case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width:
+				current += " " + word
+			default:
+				lines = append(lines, current)
+				current = word
+			}
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+	}
+	return lines
+}
+
+func datetime(value string) string {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return value
+	}
+	return t.Format("02/01/2006 15:04")
+}
@@ -0,0 +1,22 @@
+package receipt
+
+import (
+	"bytes"
+	"product-api/model"
+	texttemplate "text/template"
+)
+
+// RenderZReport prints an end-of-day Z-report as plain text.
+func RenderZReport(data model.ZReportPrint) ([]byte, error) {
+	content, err := templates.ReadFile("templates/zreport.txt.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := texttemplate.New("zreport").Funcs(funcs).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	return buf.Bytes(), err
+}